@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 )
 
 var enabled = true
@@ -38,6 +39,11 @@ func Disable() {
 	enabled = false
 }
 
+// Enabled returns a bool indicating whether the cache is enabled; for testing.
+func Enabled() bool {
+	return enabled
+}
+
 // WithFile looks (header, content) up in a user-specific file cache.
 // If found, it writes the file contents. Else it calls fn to write to
 // both the writer and the file system.
@@ -45,15 +51,7 @@ func Disable() {
 // header and content are distinct parameters to relieve the caller from
 // having to concatenate them.
 func WithFile(header string, content string, fn func() (string, error)) (string, error) {
-	h := md5.New()
-	io.WriteString(h, content) // nolint: errcheck
-	io.WriteString(h, "\n")    // nolint: errcheck
-	io.WriteString(h, header)  // nolint: errcheck
-	sum := h.Sum(nil)
-
-	// don't use ioutil.TempDir, because we want this to last across invocations
-	cachedir := cacheDir()
-	cachefile := filepath.Join(cachedir, fmt.Sprintf("%x%c%x", sum[:1], filepath.Separator, sum[1:]))
+	cachefile := cacheFile(header, content)
 
 	// ignore errors; if there's a missing file we don't care, and if it's
 	// another error we'll pick it up during write.
@@ -71,13 +69,56 @@ func WithFile(header string, content string, fn func() (string, error)) (string,
 	if err != nil {
 		return "", err
 	}
-	if err := os.MkdirAll(filepath.Dir(cachefile), 0700); err != nil {
+	if err := writeCacheFile(cachefile, s); err != nil {
 		return "", err
 	}
-	defer cacheMx.Unlock()
-	cacheMx.Lock()
-	if err := os.WriteFile(cachefile, []byte(s), 0600); err != nil {
+	return s, nil
+}
+
+// WithFileTTL is like WithFile, except that a cached value expires ttl after
+// it was written. If fn returns an error and there's an expired value,
+// WithFileTTL returns the expired value instead, so that callers that fetch
+// values from the network keep working offline.
+func WithFileTTL(header string, content string, ttl time.Duration, fn func() (string, error)) (string, error) {
+	cachefile := cacheFile(header, content)
+	var stale []byte
+	if info, err := os.Stat(cachefile); err == nil && enabled {
+		if b, err := os.ReadFile(cachefile); err == nil && len(b) > 0 {
+			if time.Since(info.ModTime()) < ttl {
+				return string(b), nil
+			}
+			stale = b
+		}
+	}
+	s, err := fn()
+	if err != nil {
+		if stale != nil {
+			return string(stale), nil
+		}
+		return "", err
+	}
+	if err := writeCacheFile(cachefile, s); err != nil {
 		return "", err
 	}
 	return s, nil
 }
+
+func cacheFile(header, content string) string {
+	h := md5.New()
+	io.WriteString(h, content) // nolint: errcheck
+	io.WriteString(h, "\n")    // nolint: errcheck
+	io.WriteString(h, header)  // nolint: errcheck
+	sum := h.Sum(nil)
+
+	// don't use ioutil.TempDir, because we want this to last across invocations
+	return filepath.Join(cacheDir(), fmt.Sprintf("%x%c%x", sum[:1], filepath.Separator, sum[1:]))
+}
+
+func writeCacheFile(cachefile, s string) error {
+	if err := os.MkdirAll(filepath.Dir(cachefile), 0700); err != nil {
+		return err
+	}
+	defer cacheMx.Unlock()
+	cacheMx.Lock()
+	return os.WriteFile(cachefile, []byte(s), 0600)
+}
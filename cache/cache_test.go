@@ -2,7 +2,9 @@ package cache
 
 import (
 	"fmt"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -54,3 +56,36 @@ func TestWithFile(t *testing.T) {
 		require.Contains(t, err.Error(), "expected error")
 	})
 }
+
+func TestWithFileTTL(t *testing.T) {
+	Enable()
+	require.NoError(t, Clear())
+	value := "v1"
+	var fetchErr error
+	fetch := func() (string, error) { return value, fetchErr }
+
+	s, err := WithFileTTL("h", "c", time.Hour, fetch)
+	require.NoError(t, err)
+	require.Equal(t, "v1", s)
+
+	value = "v2"
+	s, err = WithFileTTL("h", "c", time.Hour, fetch)
+	require.NoError(t, err)
+	require.Equal(t, "v1", s, "cache hit within the TTL")
+
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(cacheFile("h", "c"), old, old))
+	fetchErr = fmt.Errorf("offline")
+	s, err = WithFileTTL("h", "c", time.Hour, fetch)
+	require.NoError(t, err)
+	require.Equal(t, "v1", s, "expired value when fn fails")
+
+	fetchErr = nil
+	s, err = WithFileTTL("h", "c", time.Hour, fetch)
+	require.NoError(t, err)
+	require.Equal(t, "v2", s, "cache miss after the TTL")
+
+	fetchErr = fmt.Errorf("offline")
+	_, err = WithFileTTL("h", "other", time.Hour, fetch)
+	require.Error(t, err)
+}
@@ -3,7 +3,6 @@ package collection
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/pages"
@@ -34,7 +33,7 @@ type Site interface {
 // Page is in package pages.
 type Page = pages.Page
 
-const draftsPath = "_drafts"
+const draftsName = "drafts"
 const postsName = "posts"
 
 // New creates a new Collection
@@ -56,8 +55,11 @@ func (c *Collection) AbsDir() string {
 	return filepath.Join(c.cfg.SourceDir(), c.PathPrefix())
 }
 
-// PathPrefix returns the collection's directory prefix, e.g. "_posts/"
-func (c *Collection) PathPrefix() string { return filepath.FromSlash("_" + c.Name + "/") }
+// PathPrefix returns the collection's site-relative directory prefix, e.g.
+// "_posts/", or "_collections/_posts/" if collections_dir is "_collections".
+func (c *Collection) PathPrefix() string {
+	return c.cfg.CollectionDir(c.Name) + string(filepath.Separator)
+}
 
 // IsPostsCollection returns true if the collection is the special "posts" collection.
 func (c *Collection) IsPostsCollection() bool { return c.Name == postsName }
@@ -65,11 +67,27 @@ func (c *Collection) IsPostsCollection() bool { return c.Name == postsName }
 // Output returns a bool indicating whether files in this collection should be written.
 func (c *Collection) Output() bool { return templates.VariableMap(c.Metadata).Bool("output", false) }
 
-// Pages in the Post collection are ordered by date.
+// Pages returns the collection's pages, in the order that sortPages
+// describes.
 func (c *Collection) Pages() []Page {
 	return c.pages
 }
 
+// FilterPages removes the pages for which keep returns false.
+// The posts' previous and next variables are updated to match.
+func (c *Collection) FilterPages(keep func(Page) bool) {
+	var ps []Page
+	for _, p := range c.pages {
+		if keep(p) {
+			ps = append(ps, p)
+		}
+	}
+	c.pages = ps
+	if c.IsPostsCollection() {
+		addPrevNext(c.pages)
+	}
+}
+
 // Render renders the collection's pages.
 func (c *Collection) Render() error {
 	var errs []error
@@ -91,7 +109,7 @@ func (c *Collection) ToLiquid() interface{} {
 			"label":              c.Name,
 			"docs":               c.pages,
 			"files":              []string{},
-			"relative_directory": strings.TrimSuffix(c.PathPrefix(), "/"),
+			"relative_directory": filepath.ToSlash(c.cfg.CollectionDir(c.Name)),
 			"directory":          c.AbsDir(),
 		}))
 }
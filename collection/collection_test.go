@@ -1,6 +1,7 @@
 package collection
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -22,6 +23,8 @@ func TestNewCollection(t *testing.T) {
 	c1 := New(site, "c", map[string]interface{}{"output": true})
 	require.Equal(t, true, c1.Output())
 	require.Equal(t, "_c/", filepath.ToSlash(c1.PathPrefix()))
+	site.c.CollectionsDir = "_collections"
+	require.Equal(t, "_collections/_c/", filepath.ToSlash(New(site, "c", nil).PathPrefix()))
 
 	c2 := New(site, "c", map[string]interface{}{})
 	require.Equal(t, false, c2.Output())
@@ -62,3 +65,50 @@ func Test_ReadPages(t *testing.T) {
 	require.Equal(t, pages[0], pages[1].FrontMatter()["previous"])
 	require.Equal(t, nil, pages[1].FrontMatter()["next"])
 }
+
+func Test_ReadPages_sorting(t *testing.T) {
+	dir := t.TempDir()
+	for name, weight := range map[string]string{"a.md": "3", "b.md": "1", "c.md": "", "d.md": "2"} {
+		fm := "---\n---\n"
+		if weight != "" {
+			fm = "---\nweight: " + weight + "\n---\n"
+		}
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "_docs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "_docs", name), []byte(fm), 0644))
+	}
+	names := func(metadata map[string]interface{}) (result []string) {
+		c := New(siteFake{config.FromString("source: " + dir)}, "docs", metadata)
+		require.NoError(t, c.ReadPages())
+		for _, p := range c.Pages() {
+			result = append(result, filepath.Base(p.Source()))
+		}
+		return
+	}
+	require.Equal(t, []string{"a.md", "b.md", "c.md", "d.md"}, names(map[string]interface{}{}))
+	require.Equal(t, []string{"b.md", "d.md", "a.md", "c.md"}, names(map[string]interface{}{"sort_by": "weight"}))
+	require.Equal(t, []string{"d.md", "a.md", "b.md", "c.md"}, names(map[string]interface{}{
+		"sort_by": "weight",
+		"order":   []interface{}{"d.md", "a.md"},
+	}))
+}
+
+func Test_ReadPages_collectionsDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, rel := range []string{"_posts/2017-07-01-a.md", "_collections/_posts/2017-07-02-b.md", "_collections/_drafts/2017-07-03-c.md"} {
+		filename := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte("---\n---\n"), 0644))
+	}
+	site := siteFake{config.FromString("source: " + dir + "\ncollections_dir: _collections\nshow_drafts: true")}
+	c := New(site, "posts", map[string]interface{}{})
+	require.NoError(t, c.ReadPages())
+	require.Len(t, c.Pages(), 2)
+	for _, p := range c.Pages() {
+		require.Contains(t, filepath.ToSlash(p.Source()), "/_collections/")
+	}
+}
+
+func TestPermalinkPattern_pretty(t *testing.T) {
+	site := siteFake{config.FromString("permalink: pretty")}
+	require.Equal(t, "/:collection/:path/", New(site, "c", map[string]interface{}{}).PermalinkPattern())
+}
@@ -3,7 +3,6 @@ package collection
 import (
 	"os"
 	"path/filepath"
-	"sort"
 
 	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/utils"
@@ -12,15 +11,15 @@ import (
 // ReadPages scans the file system for collection pages, and adds them to c.Pages.
 func (c *Collection) ReadPages() error {
 	if c.IsPostsCollection() && c.cfg.Drafts {
-		if err := c.scanDirectory(draftsPath); err != nil {
+		if err := c.scanDirectory(c.cfg.CollectionDir(draftsName)); err != nil {
 			return err
 		}
 	}
 	if err := c.scanDirectory(c.PathPrefix()); err != nil {
 		return err
 	}
+	c.sortPages()
 	if c.IsPostsCollection() {
-		sort.Sort(pagesByDate{c.pages})
 		addPrevNext(c.pages)
 	}
 	return nil
@@ -79,7 +78,7 @@ func (c *Collection) readPost(path string, rel string) error {
 	fm := pages.FrontMatter{
 		"collection": c.Name,
 		"permalink":  c.PermalinkPattern(),
-	}.Merged(c.cfg.GetFrontMatterDefaults(c.Name, siteRel))
+	}.Merged(c.cfg.GetFrontMatterDefaults(c.cfg.FrontMatterDefaultsType(c.Name, siteRel), siteRel))
 	strategy.parseFilename(rel, fm)
 	f, err := pages.NewFile(c.site, path, filepath.ToSlash(rel), fm)
 	switch {
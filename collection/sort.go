@@ -1,5 +1,17 @@
 package collection
 
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/gojekyll/utils"
+)
+
 type pagesByDate struct{ pages []Page }
 
 // Len is part of sort.Interface.
@@ -18,3 +30,70 @@ func (p pagesByDate) Swap(i, j int) {
 	pages := p.pages
 	pages[i], pages[j] = pages[j], pages[i]
 }
+
+// sortPages orders the collection's pages. An order list of filenames,
+// relative to the collection directory, puts those pages first, in that
+// order. Otherwise sort_by orders the pages by a front matter variable;
+// pages without it come last. Pages that neither option orders are by date,
+// newest first, for posts; and by path for other collections.
+func (c *Collection) sortPages() {
+	if c.IsPostsCollection() {
+		sort.Stable(pagesByDate{c.pages})
+	}
+	md := templates.VariableMap(c.Metadata)
+	if order, ok := c.Metadata["order"].([]interface{}); ok {
+		index := map[string]int{}
+		for i, name := range order {
+			index[fmt.Sprint(name)] = i
+		}
+		position := func(p Page) int {
+			rel := filepath.ToSlash(utils.MustRel(c.AbsDir(), p.Source()))
+			if i, ok := index[rel]; ok {
+				return i
+			}
+			return len(order)
+		}
+		sort.SliceStable(c.pages, func(i, j int) bool {
+			return position(c.pages[i]) < position(c.pages[j])
+		})
+	} else if key := md.String("sort_by", ""); key != "" {
+		sort.SliceStable(c.pages, func(i, j int) bool {
+			a, aok := c.pages[i].FrontMatter()[key]
+			b, bok := c.pages[j].FrontMatter()[key]
+			switch {
+			case !aok || !bok:
+				return aok && !bok
+			default:
+				return compareValues(a, b) < 0
+			}
+		})
+	}
+}
+
+// compareValues compares two front matter values: numerically if they're
+// both numbers, chronologically if they're both times, else as strings.
+func compareValues(a, b interface{}) int {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -2,9 +2,11 @@ package collection
 
 import (
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/utils"
 )
 
@@ -63,7 +65,17 @@ const DefaultCollectionPermalinkPattern = "/:collection/:path:output_ext"
 // DefaultPostsCollectionPermalinkPattern is the default collection permalink pattern
 const DefaultPostsCollectionPermalinkPattern = "/:categories/:year/:month/:day/:title.html"
 
-func (s defaultStrategy) defaultPermalinkPattern(*config.Config) string {
+func (s defaultStrategy) defaultPermalinkPattern(cfg *config.Config) string {
+	// As in Jekyll, the site's permalink style decides whether collection
+	// URLs end with a slash or an extension.
+	if pattern, ok := cfg.String("permalink"); ok {
+		if style, found := pages.PermalinkStyles[pattern]; found {
+			pattern = style
+		}
+		if strings.HasSuffix(pattern, "/") {
+			return "/:collection/:path/"
+		}
+	}
 	return DefaultCollectionPermalinkPattern
 }
 
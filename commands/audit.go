@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/osteele/gojekyll/site"
+)
+
+var audit = app.Command("audit", "Check the site's pages for HTML and accessibility problems")
+var (
+	auditJSON        = audit.Flag("json", "Write a JSON report to this file").PlaceHolder("FILE").String()
+	auditMaxErrors   = audit.Flag("max-errors", "Fail if there are more errors than this").Default("0").Int()
+	auditMaxWarnings = audit.Flag("max-warnings", "Fail if there are more warnings than this; -1 for no limit").Default("-1").Int()
+)
+
+func auditCommand(site *site.Site) error {
+	return reportAudit(site, *auditJSON, *auditMaxErrors, *auditMaxWarnings)
+}
+
+// reportAudit prints the site's audit issues, and optionally writes them to
+// a JSON file. It returns an error, so that the command exits with a
+// non-zero status, if there are more errors or warnings than the
+// thresholds; a negative threshold is no limit.
+func reportAudit(s *site.Site, jsonFile string, maxErrors, maxWarnings int) error {
+	bannerLog.label("Auditing...", "")
+	issues, err := s.Audit()
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, issue := range issues {
+		counts[issue.Severity]++
+		if issue.Severity == site.SeverityError {
+			log.Error("%s", issue)
+		} else {
+			log.Warn("%s", issue)
+		}
+	}
+	if jsonFile != "" {
+		if err := writeAuditReport(jsonFile, issues); err != nil {
+			return err
+		}
+	}
+	errors, warnings := counts[site.SeverityError], counts[site.SeverityWarning]
+	bannerLog.label("", "%d errors, %d warnings.", errors, warnings)
+	switch {
+	case maxErrors >= 0 && errors > maxErrors:
+		return fmt.Errorf("found %d HTML errors; the limit is %d", errors, maxErrors)
+	case maxWarnings >= 0 && warnings > maxWarnings:
+		return fmt.Errorf("found %d HTML warnings; the limit is %d", warnings, maxWarnings)
+	}
+	return nil
+}
+
+func writeAuditReport(filename string, issues []site.AuditIssue) error {
+	if issues == nil {
+		issues = []site.AuditIssue{}
+	}
+	b, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(b, '\n'), 0644)
+}
@@ -10,6 +10,8 @@ import (
 var commandStartTime = time.Now()
 
 var build = app.Command("build", "Build your site").Alias("b")
+var buildCheckLinks = build.Flag("check-links", "Check the built site for broken links").Bool()
+var buildAudit = build.Flag("audit", "Check the built site for HTML and accessibility errors").Bool()
 
 func init() {
 	build.Flag("dry-run", "Dry run").Short('n').BoolVar(&options.DryRun)
@@ -30,6 +32,17 @@ func buildCommand(site *site.Site) error {
 		if site.Config().Verbose || diag.FilesExcluded+diag.FilesStaticNoFM+diag.FilesUnpublished > 0 {
 			bannerLog.label("Diagnostics:", "%s", diag.DiagSummary())
 		}
+		if *buildCheckLinks {
+			// in watch mode, the broken links have been reported; keep watching
+			if err := reportBrokenLinks(site, false); err != nil && !watch {
+				return err
+			}
+		}
+		if *buildAudit {
+			if err := reportAudit(site, "", 0, -1); err != nil && !watch {
+				return err
+			}
+		}
 	case watch:
 		log.Error("%s", err.Error())
 	default:
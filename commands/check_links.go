@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/osteele/gojekyll/site"
+)
+
+var checkLinks = app.Command("check-links", "Check the site's pages for broken links")
+var checkExternal = checkLinks.Flag("external", "Also check links to other sites").Bool()
+
+func checkLinksCommand(site *site.Site) error {
+	return reportBrokenLinks(site, *checkExternal)
+}
+
+// reportBrokenLinks prints the site's broken links. It returns an error,
+// so that the command exits with a non-zero status, if there are any.
+func reportBrokenLinks(s *site.Site, external bool) error {
+	bannerLog.label("Checking links...", "")
+	broken, err := s.CheckLinks(site.LinkCheckOptions{External: external})
+	if err != nil {
+		return err
+	}
+	for _, b := range broken {
+		log.Error("%s", b)
+	}
+	if len(broken) > 0 {
+		return fmt.Errorf("found %d broken links", len(broken))
+	}
+	bannerLog.label("", "no broken links.")
+	return nil
+}
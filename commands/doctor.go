@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/plugins"
+	"github.com/osteele/gojekyll/site"
+	"github.com/osteele/gojekyll/utils"
+)
+
+var doctor = app.Command("doctor", "Report configuration problems, and features that gojekyll doesn't emulate")
+
+// doctorCommand checks the configuration files, and then loads the site
+// and reports its compatibility issues. It returns an error, so that the
+// command exits with a non-zero status, if there are configuration
+// problems or compatibility errors.
+func doctorCommand() error {
+	problems, err := checkConfigFiles()
+	if err != nil {
+		return err
+	}
+	s, err := loadSite(*source, options)
+	if err != nil {
+		// A configuration problem, such as a required environment variable
+		// that isn't set, can prevent the site from loading.
+		if problems > 0 {
+			return fmt.Errorf("found %d configuration problems; can't load the site: %w", problems, err)
+		}
+		return err
+	}
+	bannerLog.label("Checking compatibility...", "")
+	issues, err := s.CheckCompatibility()
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, issue := range issues {
+		counts[issue.Severity]++
+		if issue.Severity == site.SeverityError {
+			log.Error("%s", issue)
+		} else {
+			log.Warn("%s", issue)
+		}
+	}
+	errors, warnings := counts[site.SeverityError], counts[site.SeverityWarning]
+	bannerLog.label("", "%d configuration problems, %d errors, %d warnings.", problems, errors, warnings)
+	if problems > 0 || errors > 0 {
+		return fmt.Errorf("found %d configuration problems and %d compatibility errors", problems, errors)
+	}
+	return nil
+}
+
+// checkConfigFiles reports misspelled configuration keys, values of the
+// wrong type, and plugins that gojekyll doesn't emulate. It reads the
+// configuration files itself, so that it can report problems that would
+// prevent the site from loading. It returns the number of problems.
+func checkConfigFiles() (int, error) {
+	bannerLog.label("Checking configuration...", "")
+	paths := config.SplitConfigFiles(options.ConfigFile)
+	if len(paths) == 0 {
+		path := filepath.Join(*source, "_config.yml")
+		if _, err := os.Stat(path); err == nil {
+			paths = []string{path}
+		}
+	}
+	count := 0
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return 0, err
+		}
+		errs, err := config.Check(b, func(name string) bool {
+			_, found := plugins.Lookup(name)
+			return found
+		})
+		if err != nil {
+			return 0, utils.WrapPathError(err, path)
+		}
+		for _, e := range errs {
+			log.Warn("%s:%d: %s", path, e.Line, e)
+		}
+		count += len(errs)
+	}
+	return count, nil
+}
@@ -24,7 +24,7 @@ var (
 )
 
 func init() {
-	app.Flag("config", "Custom configuration file").StringVar(&options.ConfigFile)
+	app.Flag("config", "Custom configuration files, separated by commas; later files take precedence").StringVar(&options.ConfigFile)
 }
 
 func init() {
@@ -36,6 +36,7 @@ func init() {
 	// these flags are just present on build and serve, but I don't see a DRY way to say this
 	app.Flag("incremental", "Enable incremental rebuild.").Short('I').Action(boolVar("incremental", &options.Incremental)).Bool()
 	app.Flag("force_polling", "Force watch to use polling").BoolVar(&options.ForcePolling)
+	app.Flag("strict-front-matter", "Fail the build if front matter doesn't match its schema").Action(boolVar("strict-front-matter", &options.StrictFrontMatter)).Bool()
 
 	// --watch has different defaults for build and serve
 	watchText := "Watch for changes and rebuild"
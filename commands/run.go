@@ -42,6 +42,8 @@ func run(cmd string) error { // nolint: gocyclo
 	switch cmd {
 	case benchmark.FullCommand():
 		return benchmarkCommand()
+	case doctor.FullCommand():
+		return doctorCommand()
 	case pluginsApp.FullCommand():
 		pluginsCommand()
 		return nil
@@ -62,8 +64,12 @@ func run(cmd string) error { // nolint: gocyclo
 
 	// These commands run *after* the site is loaded
 	switch cmd {
+	case audit.FullCommand():
+		return auditCommand(site)
 	case build.FullCommand():
 		return buildCommand(site)
+	case checkLinks.FullCommand():
+		return checkLinksCommand(site)
 	case clean.FullCommand():
 		return cleanCommand(site)
 	case render.FullCommand():
@@ -88,9 +94,15 @@ func loadSite(source string, flags config.Flags) (*site.Site, error) {
 		return nil, err
 	}
 	const configurationFileLabel = "Configuration file:"
-	if cf := site.Config().ConfigFile; cf != "" {
-		bannerLog.path(configurationFileLabel, cf)
-	} else {
+	cfs := site.Config().ConfigFiles()
+	for i, cf := range cfs {
+		label := configurationFileLabel
+		if i > 0 {
+			label = ""
+		}
+		bannerLog.path(label, cf)
+	}
+	if len(cfs) == 0 {
 		bannerLog.label(configurationFileLabel, "none")
 	}
 	bannerLog.path("Source:", site.SourceDir())
@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/k0kubun/pp"
+	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/site"
 	"github.com/osteele/gojekyll/utils"
 	"github.com/osteele/liquid"
@@ -14,9 +17,15 @@ var variables = app.Command(
 	"Print site or document variables",
 ).Alias("v").Alias("var").Alias("vars")
 
-var variablePath = variables.Arg("PATH", `Filename, URL, "site", or e.g. "site.x.y"`).String()
+var (
+	variablePath     = variables.Arg("PATH", `Filename, URL, "site", or e.g. "site.x.y"`).String()
+	variableDefaults = variables.Flag("defaults", "Print the front matter defaults that apply to PATH").Bool()
+)
 
 func variablesCommand(site *site.Site) (err error) {
+	if *variableDefaults {
+		return frontMatterDefaultsCommand(site, *variablePath)
+	}
 	var data interface{}
 	switch {
 	case strings.HasPrefix(*variablePath, "site"):
@@ -39,6 +48,55 @@ func variablesCommand(site *site.Site) (err error) {
 	return err
 }
 
+// frontMatterDefaultsCommand prints the defaults entries whose scopes match
+// a file, from lowest to highest precedence, and the variables that they set.
+func frontMatterDefaultsCommand(s *site.Site, path string) error {
+	if path == "" || strings.HasPrefix(path, "site") {
+		return fmt.Errorf("--defaults requires a filename or URL")
+	}
+	// The file needn't be a page; e.g. it could be a draft when --drafts is
+	// off.
+	rel := filepath.Clean(path)
+	if strings.HasPrefix(path, "/") {
+		d, err := pageFromPathOrRoute(s, path)
+		if err != nil {
+			return err
+		}
+		if d.Source() == "" {
+			return fmt.Errorf("%s is not generated from a source file", path)
+		}
+		rel = utils.MustRel(s.SourceDir(), d.Source())
+	}
+	var (
+		cfg        = s.Config()
+		collection = ""
+		names      = []string{"posts", config.DraftsType}
+	)
+	for name := range cfg.Collections {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if strings.HasPrefix(rel, cfg.CollectionDir(name)+string(filepath.Separator)) {
+			collection = name
+		}
+	}
+	if collection == config.DraftsType {
+		collection = "posts"
+	}
+	typename := cfg.FrontMatterDefaultsType(collection, rel)
+	entries := cfg.MatchingFrontMatterDefaults(typename, rel)
+	bannerLog.label("Defaults:", "%s (type %s), %d matching entries", filepath.ToSlash(rel), typename, len(entries))
+	for i, entry := range entries {
+		fmt.Printf("%d. path: %q, type: %q\n", i+1, entry.Scope.Path, entry.Scope.Type)
+		if _, err := pp.Println(entry.Values); err != nil {
+			return err
+		}
+	}
+	bannerLog.label("Variables:", "")
+	_, err := pp.Println(cfg.GetFrontMatterDefaults(typename, rel))
+	return err
+}
+
 // modifies its argument
 func bytesToStrings(data interface{}) {
 	if m, ok := data.(map[string]interface{}); ok {
@@ -1,11 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/osteele/gojekyll/templates"
 	"github.com/osteele/gojekyll/utils"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -20,7 +20,10 @@ type Config struct {
 	DataDir     string                            `yaml:"data_dir"`
 	IncludesDir string                            `yaml:"includes_dir"`
 	Collections map[string]map[string]interface{} `yaml:"-"`
-	Theme       string
+	// CollectionsDir, if set, is the directory that contains the collection
+	// directories, including _posts and _drafts.
+	CollectionsDir string `yaml:"collections_dir"`
+	Theme          string
 
 	// Handling Reading
 	Include     []string
@@ -33,6 +36,18 @@ type Config struct {
 	Future      bool
 	Unpublished bool
 
+	// Front matter schema violations are errors, instead of warnings
+	StrictFrontMatter bool `yaml:"strict_front_matter"`
+
+	// Multilingual sites, as in jekyll-polyglot
+	Languages               []string
+	DefaultLang             string   `yaml:"default_lang"`
+	ExcludeFromLocalization []string `yaml:"exclude_from_localization"`
+	ActiveLang              string   `yaml:"-"` // the language of this build; "" for the default language
+
+	// Environment variables that templates can read as site.env
+	EnvAllowlist []string `yaml:"env_allowlist"`
+
 	// Plugins
 	Plugins []string
 
@@ -55,12 +70,15 @@ type Config struct {
 	PermalinkTimezone string `yaml:"permalink_timezone,omitempty"`
 	Timezone          string
 	Verbose           bool
-	Defaults          []struct {
-		Scope struct {
-			Path string
-			Type string
-		}
-		Values map[string]interface{}
+	Defaults          []FrontMatterDefault
+	Liquid            struct {
+		ErrorMode       string `yaml:"error_mode"` // strict (the default), or warn
+		StrictVariables bool   `yaml:"strict_variables"`
+		StrictFilters   *bool  `yaml:"strict_filters"` // undefined filters are errors unless this is false
+	}
+	Precompress struct {
+		Formats []string // "gzip", "brotli"
+		MinSize int      `yaml:"min_size"`
 	}
 
 	// CLI-only
@@ -69,7 +87,7 @@ type Config struct {
 	Watch        bool `yaml:"-"`
 
 	// Meta
-	ConfigFile string                 `yaml:"-"`
+	ConfigFile string                 `yaml:"-"` // configuration files, separated by commas
 	m          map[string]interface{} `yaml:"-"` // config file, as map
 	ms         yaml.MapSlice          `yaml:"-"` // config file, as MapSlice
 
@@ -100,18 +118,60 @@ func (c *Config) FromDirectory(dir string) error {
 
 // FromFile updates the config from a specific configuration file.
 func (c *Config) FromFile(path string) error {
-	bytes, err := os.ReadFile(path)
-	if err != nil {
-		return err
+	return c.FromFiles([]string{path})
+}
+
+// FromFiles updates the config from a list of configuration files, as from
+// --config _config.yml,_config.production.yml. Later files take precedence;
+// their mappings are merged with those of earlier files.
+func (c *Config) FromFiles(paths []string) error {
+	var (
+		merged interface{} = yaml.MapSlice{}
+		bytes  []byte
+	)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var ms yaml.MapSlice
+		if err := yaml.Unmarshal(b, &ms); err != nil {
+			return utils.WrapPathError(err, path)
+		}
+		merged = utils.MergeYAMLValues(merged, ms)
+		bytes = b
 	}
-	if err = Unmarshal(bytes, c); err != nil {
-		return utils.WrapPathError(err, path)
+	if len(paths) > 1 {
+		var err error
+		if bytes, err = yaml.Marshal(merged); err != nil {
+			return err
+		}
+	}
+	if err := Unmarshal(bytes, c); err != nil {
+		return utils.WrapPathError(err, strings.Join(paths, ","))
 	}
-	c.ConfigFile = path
+	c.ConfigFile = strings.Join(paths, ",")
 	// Note: Source directory is set by FromDirectory, not by the config file location
 	return nil
 }
 
+// SplitConfigFiles splits the value of --config into file names.
+func SplitConfigFiles(s string) []string {
+	var paths []string
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// ConfigFiles returns the configuration files that the config was read
+// from, in the order they were merged.
+func (c *Config) ConfigFiles() []string {
+	return SplitConfigFiles(c.ConfigFile)
+}
+
 type configCompat struct {
 	Gems []string
 }
@@ -126,7 +186,15 @@ type collectionsMap struct {
 
 // IsConfigPath returns true if its arguments is a site configuration file.
 func (c *Config) IsConfigPath(rel string) bool {
-	return rel == "_config.yml"
+	if rel == "_config.yml" {
+		return true
+	}
+	for _, path := range c.ConfigFiles() {
+		if r, err := filepath.Rel(c.SourceDir(), utils.MustAbs(path)); err == nil && r == rel {
+			return true
+		}
+	}
+	return false
 }
 
 // SassDir returns the relative path of the SASS directory.
@@ -134,24 +202,20 @@ func (c *Config) SassDir() string {
 	return "_sass"
 }
 
+// Environment returns the build environment, from JEKYLL_ENV, e.g.
+// "production". The default is "development".
+func Environment() string {
+	if env := os.Getenv("JEKYLL_ENV"); env != "" {
+		return env
+	}
+	return "development"
+}
+
 // SourceDir returns the source directory as an absolute path.
 func (c *Config) SourceDir() string {
 	return utils.MustAbs(c.Source)
 }
 
-// GetFrontMatterDefaults implements https://jekyllrb.com/docs/configuration/#front-matter-defaults
-func (c *Config) GetFrontMatterDefaults(typename, rel string) (m map[string]interface{}) {
-	for _, entry := range c.Defaults {
-		scope := &entry.Scope
-		hasPrefix := strings.HasPrefix(rel, scope.Path)
-		hasType := scope.Type == "" || scope.Type == typename
-		if hasPrefix && hasType {
-			m = templates.MergeVariableMaps(m, entry.Values)
-		}
-	}
-	return
-}
-
 // RequiresFrontMatter returns a bool indicating whether the file requires front matter in order to recognize as a page.
 func (c *Config) RequiresFrontMatter(rel string) bool {
 	switch {
@@ -169,11 +233,17 @@ func (c *Config) RequiresFrontMatter(rel string) bool {
 }
 
 // Unmarshal updates site from a YAML configuration file.
+// References to environment variables in its values, such as ${API_URL},
+// are expanded first.
 func Unmarshal(bytes []byte, c *Config) error {
 	var (
 		compat configCompat
 		cList  collectionsList
 	)
+	bytes, err := expandEnv(bytes)
+	if err != nil {
+		return err
+	}
 	if err := yaml.Unmarshal(bytes, &c); err != nil {
 		return err
 	}
@@ -235,12 +305,19 @@ func (c *Config) Set(key string, val interface{}) {
 	c.ms = append(c.ms, yaml.MapItem{Key: key, Value: val})
 }
 
-// Map returns the config indexed by key, if it's a map.
+// Map returns the config indexed by key, if it's a map. The keys of a
+// mapping that was read from YAML are converted to strings.
 func (c *Config) Map(key string) (map[string]interface{}, bool) {
-	if m, ok := c.m[key]; ok {
-		if m, ok := m.(map[string]interface{}); ok {
-			return m, ok
+	switch m := c.m[key].(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		// YAML decodes nested mappings with interface{} keys
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[fmt.Sprint(k)] = v
 		}
+		return result, true
 	}
 	return nil, false
 }
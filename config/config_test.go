@@ -1,11 +1,13 @@
 package config
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
 )
 
 func TestConfig_SourceDir(t *testing.T) {
@@ -40,9 +42,53 @@ func TestUnmarshal(t *testing.T) {
 	// fmt.Println(c.Collections)
 }
 
+func TestConfig_FromFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "_config.yml")
+	production := filepath.Join(dir, "_config.production.yml")
+	require.NoError(t, os.WriteFile(base, []byte("url: http://localhost\nauthor:\n  name: A\n  email: a@example.com\n"), 0644))
+	require.NoError(t, os.WriteFile(production, []byte("url: https://example.com\nauthor:\n  email: b@example.com\n"), 0644))
+
+	c := Default()
+	require.NoError(t, c.FromFiles(SplitConfigFiles(base+", "+production)))
+	require.Equal(t, "https://example.com", c.AbsoluteURL)
+	require.Equal(t, yaml.MapSlice{{Key: "name", Value: "A"}, {Key: "email", Value: "b@example.com"}}, c.Variables()["author"])
+	require.Equal(t, []string{base, production}, c.ConfigFiles())
+
+	c.Source = dir
+	require.True(t, c.IsConfigPath("_config.yml"))
+	require.True(t, c.IsConfigPath("_config.production.yml"))
+	require.False(t, c.IsConfigPath("_config.staging.yml"))
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv("JEKYLL_ENV", "")
+	require.Equal(t, "development", Environment())
+	t.Setenv("JEKYLL_ENV", "production")
+	require.Equal(t, "production", Environment())
+}
+
 func TestConfig_IsMarkdown(t *testing.T) {
 	c := Default()
 	require.True(t, c.IsMarkdown("name.md"))
 	require.True(t, c.IsMarkdown("name.markdown"))
 	require.False(t, c.IsMarkdown("name.html"))
 }
+
+func TestConfig_Map(t *testing.T) {
+	c := FromString("feed:\n  path: atom.xml\n  2: two\ntitle: Site\n")
+	require.IsType(t, map[interface{}]interface{}{}, c.m["feed"])
+	m, ok := c.Map("feed")
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{"path": "atom.xml", "2": "two"}, m)
+
+	c.Set("feed", map[string]interface{}{"path": "feed.xml"})
+	m, ok = c.Map("feed")
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{"path": "feed.xml"}, m)
+
+	_, ok = c.Map("title")
+	require.False(t, ok)
+	_, ok = c.Map("missing")
+	require.False(t, ok)
+}
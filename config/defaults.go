@@ -0,0 +1,166 @@
+package config
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+)
+
+// A FrontMatterDefault is an entry in the defaults list.
+// See https://jekyllrb.com/docs/configuration/front-matter-defaults/
+type FrontMatterDefault struct {
+	Scope  FrontMatterScope
+	Values map[string]interface{}
+}
+
+// A FrontMatterScope selects the files that a FrontMatterDefault applies to.
+type FrontMatterScope struct {
+	// Path is a site-relative directory or file, or a glob pattern such as
+	// "section/*/special-page.html". The empty path matches every file.
+	Path string
+	// Type is pages, posts, drafts, or a collection label. The empty type
+	// matches every file.
+	Type string
+}
+
+// Front matter defaults types for files that aren't in a collection, and
+// for drafts.
+const (
+	PagesType  = "pages"
+	DraftsType = "drafts"
+)
+
+// deprecatedTypes maps the singular type names that Jekyll 2 used to their
+// current names.
+var deprecatedTypes = map[string]string{"page": PagesType, "post": "posts", "draft": DraftsType}
+
+// FrontMatterDefaultsType returns the type that front matter defaults scopes
+// match for the file at the site-relative path rel, in the named collection
+// or "".
+func (c *Config) FrontMatterDefaultsType(collection, rel string) string {
+	switch {
+	case collection == "":
+		return PagesType
+	case collection == "posts" && strings.HasPrefix(rel, c.CollectionDir(DraftsType)+string(filepath.Separator)):
+		return DraftsType
+	default:
+		return collection
+	}
+}
+
+// GetFrontMatterDefaults implements https://jekyllrb.com/docs/configuration/#front-matter-defaults
+//
+// typename is a value returned by FrontMatterDefaultsType.
+func (c *Config) GetFrontMatterDefaults(typename, rel string) (m map[string]interface{}) {
+	for _, entry := range c.MatchingFrontMatterDefaults(typename, rel) {
+		m = utils.MergeYAMLValues(m, entry.Values).(map[string]interface{})
+	}
+	return
+}
+
+// MatchingFrontMatterDefaults returns the defaults entries whose scopes
+// match a file, from lowest to highest precedence.
+//
+// As in Jekyll, an entry with a longer path takes precedence over one with
+// a shorter path; between entries with paths of the same length, one with a
+// type takes precedence over one without; and otherwise, a later entry
+// takes precedence over an earlier one.
+func (c *Config) MatchingFrontMatterDefaults(typename, rel string) []FrontMatterDefault {
+	var (
+		result []FrontMatterDefault
+		prev   *FrontMatterScope
+	)
+	rel = c.stripCollectionsDir(filepath.ToSlash(rel))
+	for i := range c.Defaults {
+		entry := c.Defaults[i]
+		if !c.scopeMatches(entry.Scope, typename, rel) {
+			continue
+		}
+		if prev == nil || entry.Scope.hasPrecedence(*prev) {
+			result = append(result, entry)
+			prev = &c.Defaults[i].Scope
+		} else {
+			result = append([]FrontMatterDefault{entry}, result...)
+		}
+	}
+	return result
+}
+
+func (c *Config) scopeMatches(scope FrontMatterScope, typename, rel string) bool {
+	if t := scope.typeName(); t != "" && t != typename && !(t == "posts" && typename == DraftsType) {
+		return false
+	}
+	scopePath := scope.sanitizedPath()
+	switch {
+	case scopePath == "":
+		return true
+	case strings.Contains(scopePath, "*"):
+		return globMatchesPrefix(c.stripCollectionsDir(scopePath), rel)
+	default:
+		return strings.HasPrefix(rel, c.stripCollectionsDir(scopePath))
+	}
+}
+
+// hasPrecedence returns true if s takes precedence over prev.
+func (s FrontMatterScope) hasPrecedence(prev FrontMatterScope) bool {
+	if n, m := len(s.sanitizedPath()), len(prev.sanitizedPath()); n != m {
+		return n > m
+	}
+	return s.Type != "" || prev.Type == ""
+}
+
+func (s FrontMatterScope) sanitizedPath() string {
+	return strings.TrimPrefix(filepath.ToSlash(s.Path), "/")
+}
+
+func (s FrontMatterScope) typeName() string {
+	if t, ok := deprecatedTypes[s.Type]; ok {
+		return t
+	}
+	return s.Type
+}
+
+// stripCollectionsDir removes the collections_dir prefix from a slash-separated
+// path, so that a scope such as _posts matches posts in collections_dir.
+func (c *Config) stripCollectionsDir(rel string) string {
+	dir := filepath.ToSlash(filepath.Clean(c.CollectionsDir))
+	if dir == "." {
+		return rel
+	}
+	return strings.TrimPrefix(rel, dir+"/")
+}
+
+// globMatchesPrefix returns true if the pattern matches rel or one of its
+// parent directories. A ** segment matches any number of directories.
+func globMatchesPrefix(pattern, rel string) bool {
+	var (
+		patterns = strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+		segments = strings.Split(rel, "/")
+	)
+	for n := 1; n <= len(segments); n++ {
+		if matchSegments(patterns, segments[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(patterns, segments []string) bool {
+	switch {
+	case len(patterns) == 0:
+		return len(segments) == 0
+	case patterns[0] == "**":
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	case len(segments) == 0:
+		return false
+	}
+	ok, err := path.Match(patterns[0], segments[0])
+	return ok && err == nil && matchSegments(patterns[1:], segments[1:])
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_GetFrontMatterDefaults(t *testing.T) {
+	c := FromString(`
+collections_dir: _collections
+defaults:
+  - scope: {path: ""}
+    values: {layout: default, nested: {a: 1, b: 2}}
+  - scope: {path: "section", type: pages}
+    values: {layout: section}
+  - scope: {path: "section"}
+    values: {layout: untyped, author: x}
+  - scope: {path: "section/*/special.html"}
+    values: {layout: special}
+  - scope: {path: "", type: post}
+    values: {layout: post, nested: {b: 3}}
+  - scope: {path: "", type: drafts}
+    values: {draft: true}
+  - scope: {path: "_posts/2020"}
+    values: {year: 2020}
+`)
+	layout := func(typename, rel string) interface{} {
+		return c.GetFrontMatterDefaults(typename, rel)["layout"]
+	}
+	require.Equal(t, "default", layout(PagesType, "index.md"))
+	require.Equal(t, "section", layout(PagesType, "section/index.md"))
+	require.Equal(t, "special", layout(PagesType, "section/a/special.html"))
+	require.Equal(t, "section", layout(PagesType, "section/a/other.html"))
+	require.Equal(t, "post", layout("posts", "_collections/_posts/2017-07-01-a.md"))
+	require.Equal(t, "default", layout("docs", "_collections/_docs/a.md"))
+
+	m := c.GetFrontMatterDefaults(PagesType, "section/index.md")
+	require.Equal(t, "x", m["author"])
+
+	m = c.GetFrontMatterDefaults(DraftsType, "_collections/_drafts/a.md")
+	require.Equal(t, "post", m["layout"])
+	require.Equal(t, true, m["draft"])
+	require.Equal(t, map[interface{}]interface{}{"a": 1, "b": 3}, m["nested"])
+
+	m = c.GetFrontMatterDefaults("posts", "_collections/_posts/2020-01-01-a.md")
+	require.Equal(t, 2020, m["year"])
+	require.Nil(t, m["draft"])
+}
+
+func TestConfig_FrontMatterDefaultsType(t *testing.T) {
+	c := Default()
+	require.Equal(t, PagesType, c.FrontMatterDefaultsType("", "index.md"))
+	require.Equal(t, "posts", c.FrontMatterDefaultsType("posts", "_posts/2017-07-01-a.md"))
+	require.Equal(t, DraftsType, c.FrontMatterDefaultsType("posts", "_drafts/a.md"))
+	require.Equal(t, "docs", c.FrontMatterDefaultsType("docs", "_docs/a.md"))
+}
+
+func Test_globMatchesPrefix(t *testing.T) {
+	require.True(t, globMatchesPrefix("section/*/special.html", "section/a/special.html"))
+	require.False(t, globMatchesPrefix("section/*/special.html", "section/a/b/special.html"))
+	require.True(t, globMatchesPrefix("section/*", "section/a/b.html"))
+	require.True(t, globMatchesPrefix("**/special.html", "section/a/special.html"))
+	require.False(t, globMatchesPrefix("docs/*.md", "section/a.md"))
+}
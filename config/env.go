@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// envRefMatcher matches ${NAME}, ${NAME:-default}, ${NAME-default},
+// ${NAME:?message}, and ${NAME?message}; and $${...}, which is an escaped ${...}.
+var envRefMatcher = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?[-?])([^}]*))?\}`)
+
+// envIntMatcher matches the expanded values that are read as ints. Other
+// values, such as 0123 and no, stay strings; YAML would read these as
+// 83 and false.
+var envIntMatcher = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// expandEnv replaces references to environment variables in the string
+// values of a YAML document, as a shell would:
+//
+//	${NAME}           the value of NAME, or "" if it isn't set
+//	${NAME:-default}  default if NAME is unset or empty
+//	${NAME-default}   default if NAME is unset
+//	${NAME:?message}  an error if NAME is unset or empty
+//	${NAME?message}   an error if NAME is unset
+//
+// A value that consists of a single reference is an int if it expands to
+// a decimal integer, and a bool if it expands to true or false, so that
+// e.g. port: ${PORT} is a number. Other values are strings.
+func expandEnv(b []byte) ([]byte, error) {
+	if !bytes.Contains(b, []byte("${")) {
+		return b, nil
+	}
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(b, &ms); err != nil {
+		return nil, err
+	}
+	v, err := expandEnvValue(ms)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(v)
+}
+
+func expandEnvValue(v interface{}) (interface{}, error) {
+	var err error
+	switch v := v.(type) {
+	case string:
+		return expandEnvString(v)
+	case yaml.MapSlice:
+		for i := range v {
+			if v[i].Value, err = expandEnvValue(v[i].Value); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if v[i], err = expandEnvValue(v[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+func expandEnvString(s string) (interface{}, error) {
+	var err error
+	loc := envRefMatcher.FindStringSubmatchIndex(s)
+	whole := loc != nil && loc[0] == 0 && loc[1] == len(s) && loc[3] == loc[2]
+	result := envRefMatcher.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRefMatcher.FindStringSubmatch(ref)
+		escape, name, op, arg := m[1], m[2], m[3], m[4]
+		if escape != "" {
+			return ref[1:]
+		}
+		value, set := os.LookupEnv(name)
+		switch op {
+		case ":-":
+			if value == "" {
+				value = arg
+			}
+		case "-":
+			if !set {
+				value = arg
+			}
+		case ":?", "?":
+			if value == "" && (op == ":?" || !set) && err == nil {
+				if arg == "" {
+					arg = "not set"
+				}
+				err = fmt.Errorf("environment variable %s: %s", name, arg)
+			}
+		}
+		return value
+	})
+	if err != nil || !whole {
+		return result, err
+	}
+	switch {
+	case result == "true", result == "false":
+		return result == "true", nil
+	case envIntMatcher.MatchString(result):
+		if n, err := strconv.Atoi(result); err == nil {
+			return n, nil
+		}
+	}
+	return result, nil
+}
+
+// EnvVariables returns the environment variables that env_allowlist names,
+// for the site.env template variable. Entries in the list can be patterns,
+// such as PUBLIC_*.
+func (c *Config) EnvVariables() map[string]interface{} {
+	env := map[string]interface{}{}
+	if len(c.EnvAllowlist) == 0 {
+		return env
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		for _, pattern := range c.EnvAllowlist {
+			if ok, err := path.Match(pattern, name); ok && err == nil {
+				env[name] = value
+				break
+			}
+		}
+	}
+	return env
+}
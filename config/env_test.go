@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestUnmarshal_env(t *testing.T) {
+	t.Setenv("GOJEKYLL_TEST_URL", "https://example.com")
+	t.Setenv("GOJEKYLL_TEST_PORT", "8080")
+	t.Setenv("GOJEKYLL_TEST_EMPTY", "")
+
+	c := Default()
+	require.NoError(t, Unmarshal([]byte(`
+url: ${GOJEKYLL_TEST_URL}
+port: ${GOJEKYLL_TEST_PORT}
+title: Site at ${GOJEKYLL_TEST_URL}/
+analytics:
+  id: ${GOJEKYLL_TEST_UNSET:-UA-0}
+  empty: ${GOJEKYLL_TEST_EMPTY-none}
+  literal: $${GOJEKYLL_TEST_URL}
+`), &c))
+	require.Equal(t, "https://example.com", c.AbsoluteURL)
+	require.Equal(t, 8080, c.Port)
+	vars := c.Variables()
+	require.Equal(t, "Site at https://example.com/", vars["title"])
+	analytics := map[string]interface{}{}
+	for _, item := range vars["analytics"].(yaml.MapSlice) {
+		analytics[item.Key.(string)] = item.Value
+	}
+	require.Equal(t, "UA-0", analytics["id"])
+	require.Equal(t, "", analytics["empty"])
+	require.Equal(t, "${GOJEKYLL_TEST_URL}", analytics["literal"])
+
+	// only decimal integers and true and false are converted
+	t.Setenv("GOJEKYLL_TEST_ZIP", "0123")
+	t.Setenv("GOJEKYLL_TEST_NO", "no")
+	t.Setenv("GOJEKYLL_TEST_OFF", "off")
+	t.Setenv("GOJEKYLL_TEST_TRUE", "true")
+	t.Setenv("GOJEKYLL_TEST_FLOAT", "1.50")
+	c = Default()
+	require.NoError(t, Unmarshal([]byte(`
+zip: ${GOJEKYLL_TEST_ZIP}
+answer: ${GOJEKYLL_TEST_NO}
+mode: ${GOJEKYLL_TEST_OFF}
+draft: ${GOJEKYLL_TEST_TRUE}
+version: ${GOJEKYLL_TEST_FLOAT}
+`), &c))
+	vars = c.Variables()
+	require.Equal(t, "0123", vars["zip"])
+	require.Equal(t, "no", vars["answer"])
+	require.Equal(t, "off", vars["mode"])
+	require.Equal(t, true, vars["draft"])
+	require.Equal(t, "1.50", vars["version"])
+
+	c = Default()
+	err := Unmarshal([]byte("url: ${GOJEKYLL_TEST_UNSET:?the site URL is required}"), &c)
+	require.EqualError(t, err, "environment variable GOJEKYLL_TEST_UNSET: the site URL is required")
+}
+
+func TestConfig_EnvVariables(t *testing.T) {
+	t.Setenv("GOJEKYLL_TEST_API", "https://api.example.com")
+	t.Setenv("GOJEKYLL_PUBLIC_ID", "123")
+	t.Setenv("GOJEKYLL_SECRET", "secret")
+
+	c := Default()
+	require.Empty(t, c.EnvVariables())
+
+	c.EnvAllowlist = []string{"GOJEKYLL_TEST_API", "GOJEKYLL_PUBLIC_*"}
+	require.Equal(t, map[string]interface{}{
+		"GOJEKYLL_TEST_API":  "https://api.example.com",
+		"GOJEKYLL_PUBLIC_ID": "123",
+	}, c.EnvVariables())
+}
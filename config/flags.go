@@ -12,6 +12,7 @@ type Flags struct {
 	Destination, Host, BaseURL  *string
 	Drafts, Future, Unpublished *bool
 	Incremental, Verbose        *bool
+	StrictFrontMatter           *bool
 	Port                        *int
 
 	// these aren't in the config file, so make them actual values
@@ -27,11 +28,14 @@ func (c *Config) ApplyFlags(f Flags) {
 	for i, n := 0, rs.NumField(); i < n; i++ {
 		field := rt.Field(i)
 		val := rs.Field(i)
-		if val.Kind() == reflect.Ptr {
-			if val.IsNil() {
-				continue
-			}
+		switch {
+		case val.Kind() == reflect.Ptr && val.IsNil():
+			continue
+		case val.Kind() == reflect.Ptr:
 			val = val.Elem()
+		case val.Kind() == reflect.String && val.Len() == 0:
+			// ConfigFile, if --config wasn't used; keep the file that was read
+			continue
 		}
 		rd.FieldByName(field.Name).Set(val)
 
@@ -67,6 +71,8 @@ func toLiquidKey(fieldName string) string {
 		return "incremental"
 	case "Destination":
 		return "destination"
+	case "StrictFrontMatter":
+		return "strict_front_matter"
 	default:
 		return ""
 	}
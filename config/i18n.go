@@ -0,0 +1,46 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// IsMultilingual returns true if the site is built once for each of
+// several languages.
+func (c *Config) IsMultilingual() bool {
+	return len(c.Languages) > 1
+}
+
+// DefaultLanguage returns the language whose pages are at the root of the
+// site, and that untranslated pages fall back to.
+func (c *Config) DefaultLanguage() string {
+	if c.DefaultLang != "" {
+		return c.DefaultLang
+	}
+	return "en"
+}
+
+// ActiveLanguage returns the language of the current build.
+func (c *Config) ActiveLanguage() string {
+	if c.ActiveLang != "" {
+		return c.ActiveLang
+	}
+	return c.DefaultLanguage()
+}
+
+// LocalizeURL returns the URL path of the file at the site-relative path
+// rel, in the active language: url under /<lang>/ for languages besides the
+// default, unless rel is excluded from localization.
+func (c *Config) LocalizeURL(rel, url string) string {
+	lang := c.ActiveLanguage()
+	if !c.IsMultilingual() || lang == c.DefaultLanguage() {
+		return url
+	}
+	rel = filepath.ToSlash(rel)
+	for _, prefix := range c.ExcludeFromLocalization {
+		if strings.HasPrefix(rel, strings.TrimPrefix(prefix, "/")) {
+			return url
+		}
+	}
+	return "/" + lang + url
+}
@@ -7,6 +7,13 @@ import (
 	"github.com/osteele/gojekyll/utils"
 )
 
+// CollectionDir returns the site-relative directory of the named collection,
+// e.g. "_posts"; or "_collections/_posts" if collections_dir is
+// "_collections". The drafts directory is CollectionDir("drafts").
+func (c *Config) CollectionDir(name string) string {
+	return filepath.Join(c.CollectionsDir, "_"+name)
+}
+
 // IsMarkdown returns a boolean indicating whether the file is a Markdown file, according to the current project.
 func (c *Config) IsMarkdown(name string) bool {
 	ext := filepath.Ext(name)
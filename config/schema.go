@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A keySchema describes the value of a configuration key. types lists the
+// YAML types that the value can have, separated by "|": string, boolean,
+// integer, array, or object. If keys is non-nil, the value is an object
+// with only those keys.
+type keySchema struct {
+	types string
+	keys  map[string]keySchema
+}
+
+func typed(types string) keySchema { return keySchema{types: types} }
+
+func object(keys map[string]keySchema) keySchema { return keySchema{"object", keys} }
+
+var (
+	arrayType   = typed("array")
+	booleanType = typed("boolean")
+	integerType = typed("integer")
+	objectType  = typed("object")
+	stringType  = typed("string")
+)
+
+// configSchema lists the configuration keys that Jekyll, gojekyll, and the
+// emulated plugins read. Other top-level keys are site variables.
+var configSchema = map[string]keySchema{
+	// Where things are
+	"source":          stringType,
+	"destination":     stringType,
+	"layouts_dir":     stringType,
+	"data_dir":        stringType,
+	"includes_dir":    stringType,
+	"collections":     typed("array|object"),
+	"collections_dir": stringType,
+	"plugins_dir":     typed("string|array"),
+	"theme":           stringType,
+
+	// Handling reading
+	"safe":                booleanType,
+	"include":             arrayType,
+	"exclude":             arrayType,
+	"keep_files":          arrayType,
+	"encoding":            stringType,
+	"markdown_ext":        stringType,
+	"strict_front_matter": booleanType,
+
+	// Filtering content
+	"show_drafts": booleanType,
+	"limit_posts": integerType,
+	"future":      booleanType,
+	"unpublished": booleanType,
+
+	// Multilingual sites
+	"languages":                 arrayType,
+	"default_lang":              stringType,
+	"exclude_from_localization": arrayType,
+
+	"env_allowlist": arrayType,
+
+	// Plugins
+	"plugins":   arrayType,
+	"gems":      arrayType,
+	"whitelist": arrayType,
+
+	// Conversion
+	"markdown":          stringType,
+	"highlighter":       stringType,
+	"lsi":               booleanType,
+	"excerpt_separator": stringType,
+	"incremental":       booleanType,
+	"kramdown":          objectType,
+	"sass": object(map[string]keySchema{
+		"sass_dir":   stringType,
+		"style":      stringType,
+		"load_paths": arrayType,
+		"sourcemap":  stringType,
+	}),
+	"liquid": object(map[string]keySchema{
+		"error_mode":       stringType,
+		"strict_variables": booleanType,
+		"strict_filters":   booleanType,
+	}),
+
+	// Serving
+	"detach":          booleanType,
+	"port":            integerType,
+	"host":            stringType,
+	"baseurl":         stringType,
+	"url":             stringType,
+	"livereload":      booleanType,
+	"livereload_port": integerType,
+
+	// Outputting
+	"permalink":          stringType,
+	"permalink_timezone": stringType,
+	"timezone":           stringType,
+	"quiet":              booleanType,
+	"verbose":            booleanType,
+	"defaults":           arrayType,
+	"precompress": object(map[string]keySchema{
+		"formats":  arrayType,
+		"min_size": integerType,
+	}),
+
+	// Site variables that the emulated plugins read. jekyll-sitemap reads
+	// url and baseurl.
+	"title":       stringType,
+	"name":        stringType,
+	"description": stringType,
+	"author":      typed("string|object"),
+	"lang":        stringType,
+	"github":      objectType,
+	"repository":  stringType,
+	"picture":     objectType,
+
+	// jekyll-feed
+	"feed": object(map[string]keySchema{
+		"path":                   stringType,
+		"posts_limit":            integerType,
+		"excerpt_only":           booleanType,
+		"categories":             typed("array|object"),
+		"collections":            typed("array|object"),
+		"tags":                   typed("boolean|object"),
+		"disable_in_development": booleanType,
+		"icon":                   stringType,
+		"logo":                   stringType,
+		"formats":                typed("array"),
+	}),
+
+	// jekyll-titles-from-headings
+	"titles_from_headings": object(map[string]keySchema{
+		"enabled":     booleanType,
+		"strip_title": booleanType,
+		"collections": booleanType,
+	}),
+
+	// jemoji
+	"emoji": object(map[string]keySchema{
+		"src":  stringType,
+		"mode": stringType,
+	}),
+
+	// jekyll-mentions
+	"jekyll-mentions": typed("string|object"),
+	"mentions":        typed("string|object"),
+
+	// jekyll-gist
+	"gist": object(map[string]keySchema{
+		"noscript": booleanType,
+	}),
+
+	// jekyll-github-metadata
+	"github_metadata": object(map[string]keySchema{
+		"api": booleanType,
+	}),
+
+	// jekyll-last-modified-at
+	"last_modified_at": object(map[string]keySchema{
+		"date_format": stringType,
+	}),
+
+	// jekyll-paginate
+	"paginate":      integerType,
+	"paginate_path": stringType,
+
+	// jekyll-redirect-from
+	"redirect_from": object(map[string]keySchema{
+		"json": booleanType,
+	}),
+
+	// jekyll-seo-tag
+	"tagline":                  stringType,
+	"logo":                     stringType,
+	"google_site_verification": stringType,
+	"seo_paginator_message":    stringType,
+	"locale":                   stringType,
+	"social": object(map[string]keySchema{
+		"name":  stringType,
+		"links": typed("array"),
+	}),
+	"twitter": object(map[string]keySchema{
+		"username": stringType,
+		"card":     stringType,
+	}),
+	"facebook": object(map[string]keySchema{
+		"app_id":    typed("string|integer"),
+		"publisher": stringType,
+		"admins":    typed("string|integer|array"),
+	}),
+	"webmaster_verifications": object(map[string]keySchema{
+		"google":   stringType,
+		"bing":     stringType,
+		"alexa":    stringType,
+		"yandex":   stringType,
+		"baidu":    stringType,
+		"facebook": stringType,
+	}),
+}
+
+// A ConfigError is a problem with a key in a configuration file.
+type ConfigError struct {
+	Key     string // dotted, e.g. "sass.sass_dir"
+	Line    int    // of the top-level key; 0 if unknown
+	Message string
+}
+
+func (e ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// Check checks the source of a configuration file against the keys that
+// Jekyll, gojekyll, and the emulated plugins read. It reports misspelled
+// keys, values of the wrong type, and plugins for which isPlugin is false.
+//
+// Top-level keys that aren't in the schema are site variables; these are
+// only reported if they are close to the name of a key in the schema.
+// References to environment variables are expanded, as in Unmarshal.
+// The errors are sorted by line.
+func Check(src []byte, isPlugin func(string) bool) ([]ConfigError, error) {
+	lines := configKeyLines(src)
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(src, &ms); err != nil {
+		return nil, err
+	}
+	var errs []ConfigError
+	for _, item := range ms {
+		k := fmt.Sprint(item.Key)
+		report := func(key, msg string) {
+			errs = append(errs, ConfigError{key, lines[k], msg})
+		}
+		// A reference to a required environment variable that isn't set,
+		// such as ${API_URL:?message}, is reported; the value's type can't
+		// be checked.
+		value, err := expandEnvValue(item.Value)
+		if err != nil {
+			report(k, err.Error())
+			continue
+		}
+		item.Value = value
+		ks, found := configSchema[k]
+		if !found {
+			maxDistance := 2
+			if len(k) <= 4 {
+				maxDistance = 1
+			}
+			if best := utils.ClosestString(k, schemaKeys(configSchema), maxDistance); best != "" {
+				report(k, fmt.Sprintf("unknown key (did you mean %q?)", best))
+			}
+			continue
+		}
+		ks.check(k, item.Value, report)
+		if k == "plugins" || k == "gems" || k == "whitelist" {
+			for _, name := range stringItems(item.Value) {
+				if !isPlugin(name) {
+					report(k, fmt.Sprintf("gojekyll doesn't emulate the %s plugin", name))
+				}
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+	return errs, nil
+}
+
+// check reports a value that doesn't have the schema's type, and the
+// unknown keys of an object.
+func (ks keySchema) check(key string, v interface{}, report func(key, msg string)) {
+	if v == nil {
+		return
+	}
+	types := strings.Split(ks.types, "|")
+	if !utils.StringArrayContains(types, configTypeName(v)) {
+		report(key, fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), configTypeName(v)))
+		return
+	}
+	ms, ok := v.(yaml.MapSlice)
+	if !ok || ks.keys == nil {
+		return
+	}
+	for _, item := range ms {
+		k := fmt.Sprint(item.Key)
+		if sub, found := ks.keys[k]; found {
+			sub.check(key+"."+k, item.Value, report)
+			continue
+		}
+		msg := "unknown key"
+		if best := utils.ClosestString(k, schemaKeys(ks.keys), 2); best != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", best)
+		}
+		report(key+"."+k, msg)
+	}
+}
+
+func schemaKeys(m map[string]keySchema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// configTypeName returns the schema type name of a value parsed from YAML.
+func configTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case time.Time:
+		return "date"
+	case []interface{}:
+		return "array"
+	case yaml.MapSlice, map[interface{}]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func stringItems(v interface{}) []string {
+	var result []string
+	if items, ok := v.([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+var configKeyLineMatcher = regexp.MustCompile(`^["']?([^\s:#"'\-][^:#"']*)["']?\s*:`)
+
+// configKeyLines returns the 1-based line numbers of the top-level keys in
+// a YAML configuration file.
+func configKeyLines(b []byte) map[string]int {
+	lines := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		if m := configKeyLineMatcher.FindStringSubmatch(scanner.Text()); m != nil {
+			k := strings.TrimSpace(m[1])
+			if _, seen := lines[k]; !seen {
+				lines[k] = n
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	isPlugin := func(name string) bool { return name == "jekyll-feed" }
+	errs, err := Check([]byte(`title: My Site
+permalinks: pretty
+exlude: [vendor]
+favorite_color: blue
+port: "4000"
+sass:
+  sass_dirr: _sass
+plugins:
+  - jekyll-feed
+  - jekyll-archives
+feed:
+  path: atom.xml
+  posts_limit: ten
+`), isPlugin)
+	require.NoError(t, err)
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	require.Equal(t, []string{
+		`permalinks: unknown key (did you mean "permalink"?)`,
+		`exlude: unknown key (did you mean "exclude"?)`,
+		`port: expected integer, got string`,
+		`sass.sass_dirr: unknown key (did you mean "sass_dir"?)`,
+		`plugins: gojekyll doesn't emulate the jekyll-archives plugin`,
+		`feed.posts_limit: expected integer, got string`,
+	}, msgs)
+	require.Equal(t, 2, errs[0].Line)
+	require.Equal(t, 6, errs[3].Line)
+
+	errs, err = Check([]byte(defaultSiteConfig), isPlugin)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+
+	// environment variables are expanded; a missing required one is reported
+	t.Setenv("GOJEKYLL_TEST_PORT", "4000")
+	errs, err = Check([]byte("port: ${GOJEKYLL_TEST_PORT}\nurl: ${GOJEKYLL_TEST_UNSET:?the site URL is required}\n"), isPlugin)
+	require.NoError(t, err)
+	require.Len(t, errs, 1)
+	require.Equal(t, "url: environment variable GOJEKYLL_TEST_UNSET: the site URL is required", errs[0].Error())
+	require.Equal(t, 2, errs[0].Line)
+}
+
+// Every key that the Config struct reads is in the schema.
+func TestConfigSchema_coversConfig(t *testing.T) {
+	ct := reflect.TypeOf(Config{})
+	for i := 0; i < ct.NumField(); i++ {
+		f := ct.Field(i)
+		name := strings.Split(f.Tag.Get("yaml"), ",")[0]
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		require.Contains(t, configSchema, name, "field %s", f.Name)
+	}
+}
@@ -8,13 +8,22 @@ import (
 
 func TestFileHasFrontMatter(t *testing.T) {
 	fm := func(filename string) bool {
-		fm, err := FileHasFrontMatter(filename)
+		fm, err := FileHasFrontMatter(filename, true)
 		require.NoError(t, err)
 		return fm
 	}
 	require.True(t, fm("testdata/empty_fm.md"))
 	require.True(t, fm("testdata/some_fm.md"))
 	require.False(t, fm("testdata/no_fm.md"))
+	require.True(t, fm("testdata/toml_fm.md"))
+	require.True(t, fm("testdata/json_fm.md"))
+
+	// only YAML front matter, unless alternates is set
+	for _, filename := range []string{"testdata/some_fm.md", "testdata/toml_fm.md", "testdata/notebook.ipynb"} {
+		hasFM, err := FileHasFrontMatter(filename, false)
+		require.NoError(t, err)
+		require.Equal(t, filename == "testdata/some_fm.md", hasFM, filename)
+	}
 }
 
 func TestFrontMatter_SortedStringArray(t *testing.T) {
@@ -2,8 +2,12 @@ package frontmatter
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"regexp"
+	"strconv"
 
+	"github.com/BurntSushi/toml"
 	"github.com/osteele/gojekyll/utils"
 
 	yaml "gopkg.in/yaml.v2"
@@ -12,22 +16,51 @@ import (
 // The first four bytes of a file with front matter.
 const fmMagic = "---\n"
 
+// The first four bytes of a file with TOML front matter, as used by Hugo.
+const tomlMagic = "+++\n"
+
 var frontMatterMatcher = regexp.MustCompile(`(?s)^---\n(.+?\n)---\n+`)
 var emptyFontMatterMatcher = regexp.MustCompile(`(?s)^---\n+---\n+`)
+var tomlFrontMatterMatcher = regexp.MustCompile(`(?s)^\+\+\+\n(.*?\n)?\+\+\+\n+`)
+
+// JSON front matter is an object whose braces are on lines of their own.
+var jsonFrontMatterMatcher = regexp.MustCompile(`(?s)^(\{\n.*?\n\})\n+`)
 
 // FileHasFrontMatter returns a bool indicating whether the
 // file looks like it has frontmatter.
-func FileHasFrontMatter(filename string) (bool, error) {
+//
+// If alternates is true, this also recognizes TOML front matter between +++
+// lines, and JSON front matter that starts with a line that is just {.
+// Callers should only set it for files that are pages, such as Markdown
+// and HTML files: many data formats, such as .json and .ipynb files, also
+// start with a { line.
+func FileHasFrontMatter(filename string, alternates bool) (bool, error) {
 	magic, err := utils.ReadFileMagic(filename)
 	if err != nil {
 		return false, err
 	}
-	return string(magic) == fmMagic, nil
+	switch {
+	case string(magic) == fmMagic:
+		return true, nil
+	case !alternates:
+		return false, nil
+	case string(magic) == tomlMagic:
+		return true, nil
+	case magic[0] == '{' && (magic[1] == '\n' || magic[1] == '\r'):
+		return true, nil
+	}
+	return false, nil
 }
 
 // Read reads the frontmatter from a document. It modifies srcPtr to point to the
 // content after the frontmatter, and sets firstLine to its 1-indexed line number.
-func Read(sourcePtr *[]byte, firstLine *int) (fm FrontMatter, err error) {
+//
+// If alternates is true, this also reads TOML and JSON front matter, as for
+// FileHasFrontMatter. Layouts and includes shouldn't set it: a JSON layout,
+// or a JSON-LD include, starts with a { line.
+//
+// The line numbers in parse errors are relative to the start of the document.
+func Read(sourcePtr *[]byte, firstLine *int, alternates bool) (fm FrontMatter, err error) {
 	var (
 		source = *sourcePtr
 		start  = 0
@@ -37,10 +70,24 @@ func Read(sourcePtr *[]byte, firstLine *int) (fm FrontMatter, err error) {
 	if match := frontMatterMatcher.FindSubmatchIndex(source); match != nil {
 		start = match[1]
 		if err = yaml.Unmarshal(source[match[2]:match[3]], &fm); err != nil {
-			return
+			return nil, offsetYAMLErrorLines(err, 1)
 		}
 	} else if match := emptyFontMatterMatcher.FindSubmatchIndex(source); match != nil {
 		start = match[1]
+	} else if !alternates {
+		// no front matter
+	} else if match := tomlFrontMatterMatcher.FindSubmatchIndex(source); match != nil {
+		start = match[1]
+		if match[2] >= 0 {
+			if fm, err = readTOML(source[match[2]:match[3]]); err != nil {
+				return nil, err
+			}
+		}
+	} else if match := jsonFrontMatterMatcher.FindSubmatchIndex(source); match != nil {
+		start = match[1]
+		if fm, err = readJSON(source[match[2]:match[3]]); err != nil {
+			return nil, err
+		}
 	}
 	if firstLine != nil {
 		*firstLine = 1 + bytes.Count(source[:start], []byte("\n"))
@@ -48,3 +95,73 @@ func Read(sourcePtr *[]byte, firstLine *int) (fm FrontMatter, err error) {
 	*sourcePtr = source[start:]
 	return
 }
+
+var yamlErrorLineMatcher = regexp.MustCompile(`\bline (\d+)\b`)
+
+// offsetYAMLErrorLines adds offset to the line numbers in a YAML error message.
+func offsetYAMLErrorLines(err error, offset int) error {
+	msg := yamlErrorLineMatcher.ReplaceAllStringFunc(err.Error(), func(s string) string {
+		n, _ := strconv.Atoi(yamlErrorLineMatcher.FindStringSubmatch(s)[1])
+		return fmt.Sprintf("line %d", n+offset)
+	})
+	return fmt.Errorf("%s", msg)
+}
+
+// readTOML parses the text between +++ lines. This starts on line 2.
+func readTOML(b []byte) (FrontMatter, error) {
+	fm := FrontMatter{}
+	if _, err := toml.Decode(string(b), (*map[string]interface{})(&fm)); err != nil {
+		if pe, ok := err.(toml.ParseError); ok {
+			return nil, fmt.Errorf("toml: line %d: %s", pe.Position.Line+1, pe.Message)
+		}
+		return nil, fmt.Errorf("toml: %w", err)
+	}
+	for k, v := range fm {
+		fm[k] = normalizeNumbers(v)
+	}
+	return fm, nil
+}
+
+// readJSON parses the object at the start of a document. This starts on line 1.
+func readJSON(b []byte) (FrontMatter, error) {
+	var fm FrontMatter
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&fm); err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			line := 1 + bytes.Count(b[:se.Offset], []byte("\n"))
+			return nil, fmt.Errorf("json: line %d: %s", line, se)
+		}
+		return nil, fmt.Errorf("json: %w", err)
+	}
+	for k, v := range fm {
+		fm[k] = normalizeNumbers(v)
+	}
+	return fm, nil
+}
+
+// normalizeNumbers replaces TOML int64s and JSON json.Numbers by ints where
+// possible, and otherwise float64s, as YAML front matter would have them.
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		if int64(int(v)) == v {
+			return int(v)
+		}
+	case json.Number:
+		if n, err := strconv.Atoi(string(v)); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = normalizeNumbers(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package frontmatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	read := func(s string) (FrontMatter, string, int) {
+		b := []byte(s)
+		line := 1
+		fm, err := Read(&b, &line, true)
+		require.NoError(t, err, s)
+		return fm, string(b), line
+	}
+	fm, body, line := read("---\ntitle: YAML\nweight: 2\n---\nBody")
+	require.Equal(t, FrontMatter{"title": "YAML", "weight": 2}, fm)
+	require.Equal(t, "Body", body)
+	require.Equal(t, 5, line)
+
+	fm, body, line = read("+++\ntitle = \"TOML\"\nweight = 2\ntags = [\"a\"]\n+++\n\nBody")
+	require.Equal(t, FrontMatter{"title": "TOML", "weight": 2, "tags": []interface{}{"a"}}, fm)
+	require.Equal(t, "Body", body)
+	require.Equal(t, 7, line)
+
+	fm, body, line = read("{\n  \"title\": \"JSON\",\n  \"weight\": 2,\n  \"ratio\": 1.5\n}\nBody")
+	require.Equal(t, FrontMatter{"title": "JSON", "weight": 2, "ratio": 1.5}, fm)
+	require.Equal(t, "Body", body)
+	require.Equal(t, 6, line)
+
+	fm, body, _ = read("+++\n+++\nBody")
+	require.Empty(t, fm)
+	require.Equal(t, "Body", body)
+
+	// only YAML front matter, unless alternates is true
+	b := []byte("{\n  \"title\": \"JSON\"\n}\nBody")
+	fm, err := Read(&b, nil, false)
+	require.NoError(t, err)
+	require.Empty(t, fm)
+	require.Equal(t, "{\n  \"title\": \"JSON\"\n}\nBody", string(b))
+}
+
+func TestRead_errors(t *testing.T) {
+	readError := func(s string) string {
+		b := []byte(s)
+		_, err := Read(&b, nil, true)
+		require.Error(t, err, s)
+		return err.Error()
+	}
+	require.Contains(t, readError("---\ntitle: a\n  - b: [\n---\nBody"), "line 3")
+	require.Contains(t, readError("+++\ntitle = \"a\"\nweight = \n+++\nBody"), "toml: line 3")
+	require.Contains(t, readError("{\n  \"title\": \"a\"\n  \"weight\": 2\n}\nBody"), "json: line 3")
+}
@@ -0,0 +1,264 @@
+package frontmatter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osteele/gojekyll/utils"
+	"github.com/osteele/liquid/evaluator"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// A Schema declares constraints on front matter variables. It uses the
+// JSON Schema keywords required, properties, and additionalProperties; and
+// for each property, type, enum, and format.
+type Schema struct {
+	Required   []string            `yaml:"required"`
+	Properties map[string]Property `yaml:"properties"`
+	// If this is false, variables that aren't listed in Properties are errors.
+	AdditionalProperties *bool `yaml:"additionalProperties"`
+}
+
+// A Property constrains the value of a single front matter variable.
+type Property struct {
+	// Type is a type name or list of type names: string, number, integer,
+	// boolean, array, object, null, or date.
+	Type   interface{}   `yaml:"type"`
+	Enum   []interface{} `yaml:"enum"`
+	Format string        `yaml:"format"` // date or date-time
+}
+
+// A SchemaError is a front matter variable that doesn't satisfy a schema.
+type SchemaError struct {
+	Key     string
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// ParseSchema reads a schema from YAML or JSON source.
+func ParseSchema(b []byte) (*Schema, error) {
+	var s Schema
+	if err := yaml.UnmarshalStrict(b, &s); err != nil {
+		return nil, err
+	}
+	for k, p := range s.Properties {
+		for _, t := range p.types() {
+			if !knownTypes[t] {
+				return nil, fmt.Errorf("property %q has unknown type %q", k, t)
+			}
+		}
+		if p.Format != "" && dateFormats[p.Format] == "" {
+			return nil, fmt.Errorf("property %q has unknown format %q", k, p.Format)
+		}
+	}
+	return &s, nil
+}
+
+var knownTypes = map[string]bool{
+	"array": true, "boolean": true, "date": true, "integer": true,
+	"null": true, "number": true, "object": true, "string": true,
+}
+
+// dateFormats maps format names to the time layouts of their string values.
+var dateFormats = map[string]string{
+	"date":      "2006-01-02",
+	"date-time": time.RFC3339,
+}
+
+// Validate checks fm against the schema. declared is the front matter that
+// the file itself declares; only its variables are checked against
+// additionalProperties, so that variables from front matter defaults, and
+// those that Jekyll adds, such as collection and next, aren't errors.
+// The errors are sorted by key.
+func (s *Schema) Validate(fm, declared FrontMatter) (errs []SchemaError) {
+	for _, k := range s.Required {
+		if v, ok := fm[k]; !ok || v == nil {
+			errs = append(errs, SchemaError{k, "required variable is missing"})
+		}
+	}
+	for k, p := range s.Properties {
+		if v, ok := fm[k]; ok {
+			if msg := p.check(v); msg != "" {
+				errs = append(errs, SchemaError{k, msg})
+			}
+		}
+	}
+	if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+		for k := range declared {
+			if _, ok := s.Properties[k]; !ok {
+				errs = append(errs, SchemaError{k, "unknown variable" + s.suggestion(k)})
+			}
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return errs
+}
+
+// suggestion returns a hint that names a property that k may be a
+// misspelling of, or "".
+func (s *Schema) suggestion(k string) string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	best := utils.ClosestString(k, names, 2)
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %q?)", best)
+}
+
+func (p Property) types() []string {
+	switch t := p.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		result := make([]string, len(t))
+		for i, item := range t {
+			result[i] = fmt.Sprint(item)
+		}
+		return result
+	}
+	return nil
+}
+
+// check returns a description of the problem with the value, or "".
+func (p Property) check(v interface{}) string {
+	if types := p.types(); len(types) > 0 {
+		ok := false
+		for _, t := range types {
+			if hasType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), typeName(v))
+		}
+	}
+	if len(p.Enum) > 0 {
+		ok := false
+		for _, e := range p.Enum {
+			if reflect.DeepEqual(e, v) || fmt.Sprint(e) == fmt.Sprint(v) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			choices := make([]string, len(p.Enum))
+			for i, e := range p.Enum {
+				choices[i] = fmt.Sprint(e)
+			}
+			return fmt.Sprintf("%v is not one of %s", v, strings.Join(choices, ", "))
+		}
+	}
+	if layout := dateFormats[p.Format]; layout != "" {
+		if s, ok := v.(string); ok {
+			if _, err := time.Parse(layout, s); err != nil {
+				return fmt.Sprintf("%q is not a %s; expected the form %s", s, p.Format, layout)
+			}
+		}
+	}
+	return ""
+}
+
+func hasType(v interface{}, t string) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "date":
+		switch v := v.(type) {
+		case time.Time:
+			return true
+		case string:
+			_, err := evaluator.ParseDate(v)
+			return err == nil
+		}
+		return false
+	case "integer":
+		switch v := v.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		}
+		return false
+	}
+	return typeName(v) == t
+}
+
+// typeName returns the JSON Schema type name of a value parsed from front matter.
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64, float64:
+		return "number"
+	case time.Time:
+		return "date"
+	case []interface{}, []string:
+		return "array"
+	case map[interface{}]interface{}, map[string]interface{}, FrontMatter:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+var (
+	keyLineMatcher     = regexp.MustCompile(`^["']?([^\s:#"'][^:#"']*)["']?\s*:`)
+	tomlKeyLineMatcher = regexp.MustCompile(`^["']?([^\s=#"'\[][^=#"']*)["']?\s*=`)
+	jsonKeyLineMatcher = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*:`)
+)
+
+// KeyLines returns the 1-based line numbers of the top-level variables in
+// a document's YAML, TOML, or JSON front matter.
+func KeyLines(source []byte) map[string]int {
+	lines := map[string]int{}
+	source = bytes.ReplaceAll(source, []byte("\r\n"), []byte("\n"))
+	var (
+		matcher *regexp.Regexp
+		end     string
+		n       = 2
+	)
+	switch {
+	case bytes.HasPrefix(source, []byte(fmMagic)):
+		matcher, end, source = keyLineMatcher, "---", source[len(fmMagic):]
+	case bytes.HasPrefix(source, []byte(tomlMagic)):
+		matcher, end, source = tomlKeyLineMatcher, "+++", source[len(tomlMagic):]
+	case bytes.HasPrefix(source, []byte("{\n")):
+		matcher, end, source = jsonKeyLineMatcher, "}", source[2:]
+	default:
+		return lines
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(source))
+	for ; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == end {
+			break
+		}
+		if matcher == tomlKeyLineMatcher && strings.HasPrefix(strings.TrimSpace(line), "[") {
+			// the following keys are in a table
+			break
+		}
+		if m := matcher.FindStringSubmatch(line); m != nil {
+			k := strings.TrimSpace(m[1])
+			if _, seen := lines[k]; !seen {
+				lines[k] = n
+			}
+		}
+	}
+	return lines
+}
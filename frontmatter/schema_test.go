@@ -0,0 +1,56 @@
+package frontmatter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `
+required: [title, layout]
+additionalProperties: false
+properties:
+  title: {type: string}
+  layout: {enum: [post, page]}
+  date: {type: string, format: date}
+  weight: {type: integer}
+  tags: {type: [array, string]}
+`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := ParseSchema([]byte(testSchema))
+	require.NoError(t, err)
+	messages := func(fm FrontMatter) (result []string) {
+		for _, err := range schema.Validate(fm, fm) {
+			result = append(result, err.Error())
+		}
+		return
+	}
+	require.Empty(t, messages(FrontMatter{"title": "T", "layout": "post", "date": "2024-01-02", "weight": 3, "tags": "a b"}))
+	require.Equal(t, []string{
+		"layout: required variable is missing",
+		"tilte: unknown variable (did you mean \"title\"?)",
+		"title: expected string, got number",
+		"weight: expected integer, got number",
+	}, messages(FrontMatter{"title": 3, "tilte": "T", "weight": 1.5}))
+	require.Equal(t, []string{
+		"date: \"Jan 2\" is not a date; expected the form 2006-01-02",
+		"layout: home is not one of post, page",
+	}, messages(FrontMatter{"title": "T", "layout": "home", "date": "Jan 2"}))
+
+	// variables that aren't declared by the file aren't unknown
+	require.Empty(t, schema.Validate(FrontMatter{"title": "T", "layout": "post", "collection": "posts"}, FrontMatter{}))
+
+	_, err = ParseSchema([]byte(`properties: {title: {type: text}}`))
+	require.Error(t, err)
+}
+
+func TestKeyLines(t *testing.T) {
+	lines := KeyLines([]byte("---\ntitle: T\ntags:\n  - a\n\"layout\": post\n---\ntitle: body\n"))
+	require.Equal(t, map[string]int{"title": 2, "tags": 3, "layout": 5}, lines)
+}
+
+func TestKeyLines_formats(t *testing.T) {
+	require.Equal(t, map[string]int{"title": 2, "date": 3}, KeyLines([]byte("+++\ntitle = \"T\"\ndate = 2024-01-01\n[params]\nx = 1\n+++\n")))
+	require.Equal(t, map[string]int{"title": 2, "tags": 3}, KeyLines([]byte("{\n  \"title\": \"T\",\n  \"tags\": []\n}\n")))
+}
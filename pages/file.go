@@ -3,8 +3,11 @@ package pages
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/frontmatter"
 )
 
@@ -24,7 +27,7 @@ type file struct {
 //
 // filename is the absolute filename. relpath is the path relative to the site or collection directory.
 func NewFile(s Site, filename string, relpath string, fm FrontMatter) (Document, error) {
-	hasFM, err := frontmatter.FileHasFrontMatter(filename)
+	hasFM, err := frontmatter.FileHasFrontMatter(filename, AllowsAlternateFrontMatter(s.Config(), relpath))
 	if err != nil {
 		return nil, err
 	}
@@ -49,6 +52,18 @@ func NewFile(s Site, filename string, relpath string, fm FrontMatter) (Document,
 	return p, nil
 }
 
+// AllowsAlternateFrontMatter returns a bool indicating whether a page can
+// have TOML or JSON front matter. Only Markdown and HTML files can; other
+// files that start with a { line, such as notebooks, stay static.
+func AllowsAlternateFrontMatter(c *config.Config, rel string) bool {
+	switch strings.ToLower(filepath.Ext(rel)) {
+	case ".html", ".htm":
+		return true
+	default:
+		return c.IsMarkdown(rel)
+	}
+}
+
 func (f *file) String() string {
 	return fmt.Sprintf("%T{Path=%v, Permalink=%v}", f, f.relPath, f.permalink)
 }
@@ -43,3 +43,16 @@ func (rm renderManagerFake) Render(w io.Writer, src []byte, vars liquid.Bindings
 func (rm renderManagerFake) RenderTemplate(src []byte, vars liquid.Bindings, filename string, lineNo int) ([]byte, error) {
 	return append([]byte("rendered: "), src...), nil
 }
+
+func TestNewFile_alternateFrontMatter(t *testing.T) {
+	site := siteFake{t, config.Default()}
+	p, err := NewFile(site, "testdata/json_fm.html", "json_fm.html", map[string]interface{}{})
+	require.NoError(t, err)
+	require.Implements(t, (*Page)(nil), p)
+	require.Equal(t, "JSON", p.(Page).FrontMatter()["title"])
+
+	// a notebook starts with a { line, but isn't a page
+	p, err = NewFile(site, "testdata/notebook.ipynb", "notebook.ipynb", map[string]interface{}{})
+	require.NoError(t, err)
+	require.IsType(t, &StaticFile{}, p)
+}
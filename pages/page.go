@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/frontmatter"
 	"github.com/osteele/gojekyll/utils"
 	"github.com/osteele/gojekyll/version"
@@ -119,7 +120,7 @@ func readFrontMatter(f *file) (b []byte, lineNo int, err error) {
 		return
 	}
 	lineNo = 1
-	fm, err := frontmatter.Read(&b, &lineNo)
+	fm, err := frontmatter.Read(&b, &lineNo, AllowsAlternateFrontMatter(f.site.Config(), f.relPath))
 	if err != nil {
 		return
 	}
@@ -148,16 +149,18 @@ func (p *page) Tags() []string {
 
 // TemplateContext returns the local variables for template evaluation
 func (p *page) TemplateContext() map[string]interface{} {
-	env := os.Getenv("JEKYLL_ENV")
-	if env == "" {
-		env = "development"
-	}
 	return map[string]interface{}{
-		"page": p,
-		"site": p.site,
-		"jekyll": map[string]string{
-			"environment": env,
-			"version":     fmt.Sprintf("%s (gojekyll)", version.Version)},
+		"page":   p,
+		"site":   p.site,
+		"jekyll": JekyllVariable(),
+	}
+}
+
+// JekyllVariable returns the value of the jekyll template variable.
+func JekyllVariable() map[string]string {
+	return map[string]string{
+		"environment": config.Environment(),
+		"version":     fmt.Sprintf("%s (gojekyll)", version.Version),
 	}
 }
 
@@ -213,6 +216,19 @@ func (p *page) Render() error {
 	return p.contentError
 }
 
+// RawContent returns the page's source, after the front matter, before it
+// is rendered.
+func (p *page) RawContent() []byte {
+	return p.raw
+}
+
+// SetRawContent replaces the page's source, so that a plugin can modify it
+// before it is rendered.
+func (p *page) SetRawContent(raw []byte) {
+	p.raw = raw
+	p.reset()
+}
+
 func (p *page) SetContent(content string) {
 	p.m.Lock()
 	defer p.m.Unlock()
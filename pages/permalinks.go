@@ -110,7 +110,12 @@ func (p *page) computePermalink(vars map[string]string) (src string, err error)
 	if err != nil {
 		return "", err
 	}
-	return utils.URLPathClean("/" + s), nil
+	url := utils.URLPathClean("/" + s)
+	if cfg := p.site.Config(); cfg.IsMultilingual() {
+		// a build for a language other than the default puts pages under /<lang>/
+		url = cfg.LocalizeURL(p.site.RelativePath(p.filename), url)
+	}
+	return url, nil
 }
 
 // removePostOnlyPlaceholders removes date and category placeholders from permalink patterns
@@ -15,6 +15,7 @@ type siteFake struct {
 	e *liquid.Engine
 }
 
+func (s siteFake) AddGeneratedFile(string, []byte)               {}
 func (s siteFake) AddHTMLPage(string, string, pages.FrontMatter) {}
 func (s siteFake) Config() *config.Config                        { return &s.c }
 func (s siteFake) HasLayout(string) bool                         { return true }
@@ -29,8 +30,9 @@ func (s siteFake) FilenameURLPath(string) (string, bool) { return "", false }
 func TestAvatarTag(t *testing.T) {
 	engine := liquid.NewEngine()
 	plugins := []string{"jekyll-avatar"}
-	_ = Install(plugins, siteFake{config.Default(), engine})
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 	bindings := liquid.Bindings{"user": "osteele"}
 
 	s, err := engine.ParseAndRenderString(`{% avatar osteele %}`, bindings)
@@ -17,6 +17,7 @@ type mockSite struct {
 	layouts map[string]bool
 }
 
+func (m *mockSite) AddGeneratedFile(url string, content []byte)              {}
 func (m *mockSite) AddHTMLPage(url string, tpl string, fm pages.FrontMatter) {}
 func (m *mockSite) Config() *config.Config {
 	if m.cfg == nil {
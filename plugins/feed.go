@@ -3,7 +3,16 @@ package plugins
 import (
 	"fmt"
 	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/gojekyll/utils"
 	"github.com/osteele/liquid"
 	"github.com/osteele/liquid/render"
 )
@@ -33,29 +42,308 @@ func (p *jekyllFeedPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
 	return nil
 }
 
+// A feedSpec describes one of the feeds that the feed configuration
+// generates. Category and tag are empty for a collection's main feed.
+type feedSpec struct {
+	path       string
+	collection string
+	category   string
+	tag        string
+}
+
+// feedTagMatcher matches tags that jekyll-feed creates feeds for. Other tags
+// would make awkward paths.
+var feedTagMatcher = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
 func (p *jekyllFeedPlugin) PostReadSite(s Site) error {
-	path := "/feed.xml"
-	if cfg, ok := s.Config().Map("feed"); ok {
-		if pp, ok := cfg["path"].(string); ok {
-			path = "/" + pp
+	options := feedOptions(s.Config())
+	if options.Bool("disable_in_development", false) && config.Environment() == "development" {
+		return nil
+	}
+	limit := 10
+	if n, ok := options["posts_limit"].(int); ok {
+		limit = n
+	}
+	xsl := fileExists(s, "feed.xslt.xml")
+	for _, f := range feeds(s) {
+		for _, format := range feedFormats(options) {
+			path := format.path(f.path)
+			// a file in the source directory takes precedence
+			if fileExists(s, path) {
+				continue
+			}
+			s.AddHTMLPage(path, format.template, map[string]interface{}{
+				"layout":       nil,
+				"sitemap":      false,
+				"xsl":          xsl,
+				"title":        feedTitle(s.Config(), f),
+				"collection":   f.collection,
+				"category":     nilIfEmpty(f.category),
+				"tags":         nilIfEmpty(f.tag),
+				"excerpt_only": options.Bool("excerpt_only", false),
+				"posts":        feedEntries(s, f, limit),
+			})
 		}
 	}
-	s.AddHTMLPage(path, feedTemplateSource, nil)
 	return nil
 }
 
+// A feedFormat is an output format that the formats option can name.
+type feedFormat struct {
+	name      string
+	mediaType string
+	ext       string
+	template  string
+}
+
+var allFeedFormats = []feedFormat{
+	{"atom", "application/atom+xml", ".xml", feedTemplateSource},
+	{"rss", "application/rss+xml", ".rss.xml", rssTemplateSource},
+	{"json", "application/feed+json", ".json", jsonFeedTemplateSource},
+}
+
+// path returns the path of a feed in this format, from the path of the
+// Atom feed. For example, the RSS and JSON versions of /feed.xml are
+// /feed.rss.xml and /feed.json.
+func (f feedFormat) path(atomPath string) string {
+	return strings.TrimSuffix(atomPath, ".xml") + f.ext
+}
+
+// feedFormats returns the formats that the formats option names, in the
+// order that allFeedFormats lists them. The default is Atom.
+func feedFormats(options templates.VariableMap) []feedFormat {
+	names := stringList(options["formats"])
+	if names == nil {
+		names = []string{"atom"}
+	}
+	var out []feedFormat
+	for _, format := range allFeedFormats {
+		if utils.StringArrayContains(names, format.name) {
+			out = append(out, format)
+		}
+	}
+	return out
+}
+
+// feedTitle returns the title of a feed: the site title, followed by the
+// collection and category names that select its entries.
+func feedTitle(cfg *config.Config, f feedSpec) string {
+	title, _ := cfg.String("name")
+	if s, ok := cfg.String("title"); ok {
+		title = s
+	}
+	capitalize := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + strings.ToLower(s[1:])
+	}
+	if f.collection != "posts" {
+		title += " | " + capitalize(f.collection)
+	}
+	if f.category != "" {
+		title += " | " + capitalize(f.category)
+	}
+	return title
+}
+
+// feeds returns the feeds for each configured collection and its
+// categories, and for each post tag if the tags option is set.
+func feeds(s Site) []feedSpec {
+	var (
+		options     = feedOptions(s.Config())
+		collections = feedCollections(options)
+		names       = make([]string, 0, len(collections))
+		out         []feedSpec
+	)
+	for name := range collections {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// posts first, then the other collections by name
+		return names[i] == "posts" || (names[j] != "posts" && names[i] < names[j])
+	})
+	for _, name := range names {
+		meta := collections[name]
+		prefix := "/feed"
+		if name != "posts" {
+			prefix = "/feed/" + name
+		}
+		out = append(out, feedSpec{path: feedPath(name, meta), collection: name})
+		for _, category := range stringList(meta["categories"]) {
+			out = append(out, feedSpec{prefix + "/" + category + ".xml", name, category, ""})
+		}
+	}
+	if tags := options["tags"]; tags != nil && tags != false {
+		tagOptions := templates.VariableMap(stringKeyMap(tags))
+		path := "/" + strings.Trim(tagOptions.String("path", "feed/by_tag"), "/") + "/"
+		only := stringList(tagOptions["only"])
+		if only == nil {
+			only = postTags(s)
+		}
+		except := utils.StringSet{}
+		except.AddStrings(stringList(tagOptions["except"]))
+		for _, tag := range only {
+			if except.Contains(tag) || !feedTagMatcher.MatchString(tag) {
+				continue
+			}
+			out = append(out, feedSpec{path: path + tag + ".xml", collection: "posts", tag: tag})
+		}
+	}
+	return out
+}
+
+// feedPath returns the path of a collection's main feed, from its options in
+// the collections option.
+func feedPath(collection string, meta map[string]interface{}) string {
+	if p, ok := meta["path"].(string); ok && p != "" {
+		return "/" + strings.TrimPrefix(p, "/")
+	}
+	if collection == "posts" {
+		return "/feed.xml"
+	}
+	return "/feed/" + collection + ".xml"
+}
+
+// feedCollections returns the collections option, as a map of collection
+// names to their options. The posts collection is always present, and uses
+// the top-level path and categories options unless it overrides them.
+func feedCollections(options templates.VariableMap) map[string]map[string]interface{} {
+	collections := map[string]map[string]interface{}{}
+	switch value := options["collections"].(type) {
+	case []interface{}:
+		for _, name := range value {
+			collections[fmt.Sprint(name)] = map[string]interface{}{}
+		}
+	default:
+		for name, meta := range stringKeyMap(value) {
+			m := stringKeyMap(meta)
+			if m == nil {
+				m = map[string]interface{}{}
+			}
+			collections[name] = m
+		}
+	}
+	posts, ok := collections["posts"]
+	if !ok {
+		posts = map[string]interface{}{}
+		collections["posts"] = posts
+	}
+	for _, k := range []string{"path", "categories"} {
+		if _, ok := posts[k]; !ok {
+			posts[k] = options[k]
+		}
+	}
+	return collections
+}
+
+// feedEntries returns the documents that a feed lists, newest first.
+func feedEntries(s Site, f feedSpec, limit int) []Page {
+	var docs []Page
+	if f.collection == "posts" {
+		docs = s.Posts()
+	} else {
+		for _, p := range s.Pages() {
+			if p.FrontMatter()["collection"] == f.collection {
+				docs = append(docs, p)
+			}
+		}
+		sort.SliceStable(docs, func(i, j int) bool {
+			a, _ := docs[i].FrontMatter()["date"].(time.Time)
+			b, _ := docs[j].FrontMatter()["date"].(time.Time)
+			return a.After(b)
+		})
+	}
+	var out []Page
+	for _, p := range docs {
+		switch {
+		case len(out) >= limit:
+			return out
+		case p.FrontMatter().Bool("draft", false):
+		case f.category != "" && !utils.StringArrayContains(p.Categories(), f.category):
+		case f.tag != "" && !utils.StringArrayContains(p.Tags(), f.tag):
+		default:
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// postTags returns the site's post tags, sorted.
+func postTags(s Site) []string {
+	set := utils.StringSet{}
+	for _, p := range s.Posts() {
+		set.AddStrings(p.Tags())
+	}
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// feedMetaTag renders {% feed_meta %}, links to the posts feed in each of
+// its formats.
 func (p *jekyllFeedPlugin) feedMetaTag(ctx render.Context) (string, error) {
 	cfg := p.site.Config()
 	title, _ := cfg.String("name")
 	if s, ok := cfg.String("title"); ok {
 		title = s
 	}
-	tag := fmt.Sprintf(`<link type="application/atom+xml" rel="alternate" href="%s/feed.xml" title="%s">`,
-		html.EscapeString(cfg.AbsoluteURL), html.EscapeString(title))
-	return tag, nil
+	var (
+		options = feedOptions(cfg)
+		path    = feedPath("posts", feedCollections(options)["posts"])
+		links   []string
+	)
+	for _, format := range feedFormats(options) {
+		href := utils.URLJoin(cfg.AbsoluteURL, cfg.BaseURL, format.path(path))
+		tag := fmt.Sprintf(`<link type="%s" rel="alternate" href="%s"`, format.mediaType, html.EscapeString(href))
+		if title != "" {
+			tag += fmt.Sprintf(` title="%s"`, html.EscapeString(title))
+		}
+		links = append(links, tag+" />")
+	}
+	return strings.Join(links, "\n"), nil
+}
+
+func feedOptions(cfg *config.Config) templates.VariableMap {
+	m, _ := cfg.Map("feed")
+	return templates.VariableMap(m)
+}
+
+// fileExists returns true if the site's source directory has a file at
+// the URL path.
+func fileExists(s Site, path string) bool {
+	_, err := os.Stat(filepath.Join(s.Config().SourceDir(), filepath.FromSlash(path)))
+	return err == nil
 }
 
-// Taken verbatim from https://github.com/jekyll/jekyll-feed/
+// stringList returns a configuration value as a list of strings. A single
+// string is a list of one item.
+func stringList(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	}
+	return nil
+}
+
+func nilIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Adapted from https://github.com/jekyll/jekyll-feed/. The plugin selects
+// page.posts, instead of the template filtering site[page.collection].
 const feedTemplateSource = `<?xml version="1.0" encoding="utf-8"?>
 {% if page.xsl %}
   <?xml-stylesheet type="text/xml" href="{{ '/feed.xslt.xml' | absolute_url }}"?>
@@ -65,12 +353,20 @@ const feedTemplateSource = `<?xml version="1.0" encoding="utf-8"?>
   <link href="{{ page.url | absolute_url }}" rel="self" type="application/atom+xml" />
   <link href="{{ '/' | absolute_url }}" rel="alternate" type="text/html" {% if site.lang %}hreflang="{{ site.lang }}" {% endif %}/>
   <updated>{{ site.time | date_to_xmlschema }}</updated>
-  <id>{{ '/' | absolute_url | xml_escape }}</id>
+  <id>{{ page.url | absolute_url | xml_escape }}</id>
+
+  {% assign title = site.title | default: site.name %}
+  {% if page.collection != "posts" %}
+    {% assign collection = page.collection | capitalize %}
+    {% assign title = title | append: " | " | append: collection %}
+  {% endif %}
+  {% if page.category %}
+    {% assign category = page.category | capitalize %}
+    {% assign title = title | append: " | " | append: category %}
+  {% endif %}
 
-  {% if site.title %}
-    <title type="html">{{ site.title | smartify | xml_escape }}</title>
-  {% elsif site.name %}
-    <title type="html">{{ site.name | smartify | xml_escape }}</title>
+  {% if title %}
+    <title type="html">{{ title | smartify | xml_escape }}</title>
   {% endif %}
 
   {% if site.description %}
@@ -89,15 +385,17 @@ const feedTemplateSource = `<?xml version="1.0" encoding="utf-8"?>
     </author>
   {% endif %}
 
-  {% assign posts = site.posts | where_exp: "post", "post.draft != true" %}
-  {% for post in posts limit: 10 %}
+  {% for post in page.posts %}
     <entry{% if post.lang %}{{" "}}xml:lang="{{ post.lang }}"{% endif %}>
       <title type="html">{{ post.title | smartify | strip_html | normalize_whitespace | xml_escape }}</title>
       <link href="{{ post.url | absolute_url }}" rel="alternate" type="text/html" title="{{ post.title | xml_escape }}" />
       <published>{{ post.date | date_to_xmlschema }}</published>
       <updated>{{ post.last_modified_at | default: post.date | date_to_xmlschema }}</updated>
       <id>{{ post.id | absolute_url | xml_escape }}</id>
-      <content type="html" xml:base="{{ post.url | absolute_url | xml_escape }}">{{ post.content | strip | xml_escape }}</content>
+      {% assign excerpt_only = post.feed.excerpt_only | default: page.excerpt_only %}
+      {% unless excerpt_only %}
+        <content type="html" xml:base="{{ post.url | absolute_url | xml_escape }}">{{ post.content | strip | xml_escape }}</content>
+      {% endunless %}
 
       {% assign post_author = post.author | default: post.authors[0] | default: site.author %}
       {% assign post_author = site.data.authors[post_author] | default: post_author %}
@@ -137,3 +435,86 @@ const feedTemplateSource = `<?xml version="1.0" encoding="utf-8"?>
     </entry>
   {% endfor %}
 </feed>`
+
+// rssTemplateSource renders an RSS 2.0 feed of page.posts.
+const rssTemplateSource = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>{{ page.title | smartify | xml_escape }}</title>
+    <link>{{ '/' | absolute_url | xml_escape }}</link>
+    <atom:link href="{{ page.url | absolute_url | xml_escape }}" rel="self" type="application/rss+xml" />
+    <description>{{ site.description | default: page.title | xml_escape }}</description>
+    {% if site.lang %}
+      <language>{{ site.lang | xml_escape }}</language>
+    {% endif %}
+    <lastBuildDate>{{ site.time | date: "%a, %d %b %Y %H:%M:%S %z" }}</lastBuildDate>
+    <generator>Jekyll v{{ jekyll.version }}</generator>
+    {% for post in page.posts %}
+      <item>
+        <title>{{ post.title | smartify | strip_html | normalize_whitespace | xml_escape }}</title>
+        <link>{{ post.url | absolute_url | xml_escape }}</link>
+        <guid isPermaLink="true">{{ post.url | absolute_url | xml_escape }}</guid>
+        <pubDate>{{ post.date | date: "%a, %d %b %Y %H:%M:%S %z" }}</pubDate>
+        {% assign excerpt_only = post.feed.excerpt_only | default: page.excerpt_only %}
+        {% if excerpt_only %}
+          <description>{{ post.excerpt | strip | xml_escape }}</description>
+        {% else %}
+          <description>{{ post.content | strip | xml_escape }}</description>
+        {% endif %}
+        {% for category in post.categories %}
+          <category>{{ category | xml_escape }}</category>
+        {% endfor %}
+        {% for tag in post.tags %}
+          <category>{{ tag | xml_escape }}</category>
+        {% endfor %}
+      </item>
+    {% endfor %}
+  </channel>
+</rss>`
+
+// jsonFeedTemplateSource renders a JSON Feed 1.1 feed of page.posts.
+const jsonFeedTemplateSource = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": {{ page.title | smartify | jsonify }},
+  "home_page_url": {{ '/' | absolute_url | jsonify }},
+  "feed_url": {{ page.url | absolute_url | jsonify }}
+  {% if site.description %}, "description": {{ site.description | jsonify }}{% endif %}
+  {% if site.lang %}, "language": {{ site.lang | jsonify }}{% endif %}
+  {% if site.author %}
+    , "authors": [{ "name": {{ site.author.name | default: site.author | jsonify }}
+      {% if site.author.uri %}, "url": {{ site.author.uri | jsonify }}{% endif %} }]
+  {% endif %},
+  "items": [
+    {% for post in page.posts %}
+      {
+        "id": {{ post.url | absolute_url | jsonify }},
+        "url": {{ post.url | absolute_url | jsonify }},
+        "title": {{ post.title | smartify | strip_html | normalize_whitespace | jsonify }},
+        {% assign excerpt_only = post.feed.excerpt_only | default: page.excerpt_only %}
+        {% if excerpt_only %}
+          "content_html": {{ post.excerpt | strip | jsonify }},
+        {% else %}
+          "content_html": {{ post.content | strip | jsonify }},
+        {% endif %}
+        {% if post.excerpt and post.excerpt != empty %}
+          "summary": {{ post.excerpt | strip_html | normalize_whitespace | jsonify }},
+        {% endif %}
+        {% assign post_image = post.image.path | default: post.image %}
+        {% if post_image %}
+          {% unless post_image contains "://" %}
+            {% assign post_image = post_image | absolute_url %}
+          {% endunless %}
+          "image": {{ post_image | jsonify }},
+        {% endif %}
+        {% if post.tags.size > 0 %}
+          "tags": {{ post.tags | jsonify }},
+        {% endif %}
+        {% if post.lang %}
+          "language": {{ post.lang | jsonify }},
+        {% endif %}
+        "date_published": {{ post.date | date_to_xmlschema | jsonify }},
+        "date_modified": {{ post.last_modified_at | default: post.date | date_to_xmlschema | jsonify }}
+      }{% unless forloop.last %},{% endunless %}
+    {% endfor %}
+  ]
+}`
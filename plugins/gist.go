@@ -6,26 +6,47 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/logger"
 	"github.com/osteele/gojekyll/tags"
+	"github.com/osteele/gojekyll/templates"
 	"github.com/osteele/liquid"
 	"github.com/osteele/liquid/render"
-	liquidtags "github.com/osteele/liquid/tags"
 )
 
 func init() {
-	register("jekyll-gist", jekyllGistPlugin{})
+	register("jekyll-gist", &jekyllGistPlugin{})
 }
 
-type jekyllGistPlugin struct{ plugin }
+type jekyllGistPlugin struct {
+	plugin
+	site Site
+	// offline is set after a request fails to connect, so that the other
+	// gists in the build don't wait for the timeout too.
+	offline int32
+}
+
+// gistCacheTTL is how long the contents of a gist are cached on disk.
+const gistCacheTTL = 24 * time.Hour
+
+// gistRawURL is the host of raw gist content; tests replace it.
+var gistRawURL = "https://gist.githubusercontent.com"
+
+func (p *jekyllGistPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	atomic.StoreInt32(&p.offline, 0)
+	return nil
+}
 
-func (p jekyllGistPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
-	e.RegisterTag("gist", gistTag)
+func (p *jekyllGistPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
+	e.RegisterTag("gist", p.gistTag)
 	return nil
 }
 
-func gistTag(ctx render.Context) (string, error) {
+func (p *jekyllGistPlugin) gistTag(ctx render.Context) (string, error) {
 	argsline, err := ctx.ExpandTagArg()
 	if err != nil {
 		return "", err
@@ -51,21 +72,11 @@ func gistTag(ctx render.Context) (string, error) {
 	}
 	output := fmt.Sprintf(`<script src="%s"> </script>`, scriptURL)
 
-	// Check if noscript is enabled in config
-	noscriptEnabled := false
-	if site := ctx.Get("site"); site != nil {
-		if siteDrop, ok := liquid.FromDrop(site).(liquidtags.IterationKeyedMap); ok {
-			if gistConfig, ok := siteDrop["gist"].(map[string]interface{}); ok {
-				if noscript, ok := gistConfig["noscript"].(bool); ok {
-					noscriptEnabled = noscript
-				}
-			}
-		}
-	}
-
-	// If noscript is enabled, fetch and include the raw gist content
-	if noscriptEnabled {
-		code, err := fetchGistContent(gistID, filename)
+	// As in jekyll-gist, the noscript fallback is on unless gist.noscript
+	// is false.
+	options, _ := p.site.Config().Map("gist")
+	if templates.VariableMap(options).Bool("noscript", true) {
+		code, err := p.gistContent(gistID, filename)
 		if err == nil && code != "" {
 			escapedCode := html.EscapeString(code)
 			output += fmt.Sprintf("<noscript><pre>%s</pre></noscript>", escapedCode)
@@ -76,16 +87,38 @@ func gistTag(ctx render.Context) (string, error) {
 	return output, nil
 }
 
-// fetchGistContent retrieves the raw content of a gist from GitHub
-func fetchGistContent(gistID, filename string) (string, error) {
-	// Build the raw content URL
+// gistContent returns the raw content of a gist, from the disk cache if it
+// was fetched within gistCacheTTL. If GitHub can't be reached, it returns
+// the expired cached content, if any.
+func (p *jekyllGistPlugin) gistContent(gistID, filename string) (string, error) {
 	// Format: https://gist.githubusercontent.com/{user}/{id}/raw/{file}
 	// If no filename is specified, GitHub returns the first file
-	url := fmt.Sprintf("https://gist.githubusercontent.com/%s/raw", gistID)
+	url := fmt.Sprintf("%s/%s/raw", gistRawURL, gistID)
 	if filename != "" {
 		url += "/" + filename
 	}
+	return cache.WithFileTTL("gist", url, gistCacheTTL, func() (string, error) {
+		if atomic.LoadInt32(&p.offline) != 0 {
+			return "", fmt.Errorf("offline")
+		}
+		code, err := fetchGistContent(url)
+		if _, ok := err.(gistStatusError); err != nil && !ok {
+			if atomic.CompareAndSwapInt32(&p.offline, 0, 1) {
+				logger.Default().Warn("jekyll-gist: can't fetch %s (%s); gists won't have noscript fallbacks", url, err)
+			}
+		}
+		return code, err
+	})
+}
 
+type gistStatusError int
+
+func (e gistStatusError) Error() string {
+	return fmt.Sprintf("failed to fetch gist: status %d", int(e))
+}
+
+// fetchGistContent retrieves the raw content of a gist from GitHub
+func fetchGistContent(url string) (string, error) {
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 3 * time.Second,
@@ -98,7 +131,7 @@ func fetchGistContent(gistID, filename string) (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch gist: status %d", resp.StatusCode)
+		return "", gistStatusError(resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
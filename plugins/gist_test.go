@@ -1,19 +1,46 @@
 package plugins
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
+	"github.com/osteele/gojekyll/cache"
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/liquid"
 	"github.com/stretchr/testify/require"
 )
 
+// serveGists serves the raw content of the gist user/abc, which has the
+// file hello.rb, in place of GitHub, and gives the test its own cache
+// directory. It returns the server and a count of its requests.
+func serveGists(t *testing.T) (*httptest.Server, *int) {
+	t.Setenv("TMPDIR", t.TempDir())
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/user/abc/raw/hello.rb" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `puts "<hello>"`)
+	}))
+	t.Cleanup(server.Close)
+	defaultURL := gistRawURL
+	t.Cleanup(func() { gistRawURL = defaultURL })
+	gistRawURL = server.URL
+	return server, &requests
+}
+
 func TestGistTag(t *testing.T) {
+	serveGists(t)
 	engine := liquid.NewEngine()
 	plugins := []string{"jekyll-gist"}
-	_ = Install(plugins, siteFake{config.Default(), engine})
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 
 	s, err := engine.ParseAndRenderString(`{% gist parkr/931c1c8d465a04042403 %}`, liquid.Bindings{})
 	require.NoError(t, err)
@@ -23,10 +50,12 @@ func TestGistTag(t *testing.T) {
 }
 
 func TestGistTagWithFilename(t *testing.T) {
+	serveGists(t)
 	engine := liquid.NewEngine()
 	plugins := []string{"jekyll-gist"}
-	_ = Install(plugins, siteFake{config.Default(), engine})
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 
 	s, err := engine.ParseAndRenderString(`{% gist parkr/931c1c8d465a04042403 test.rb %}`, liquid.Bindings{})
 	require.NoError(t, err)
@@ -39,8 +68,9 @@ func TestGistTagNoscriptDisabled(t *testing.T) {
 	engine := liquid.NewEngine()
 	plugins := []string{"jekyll-gist"}
 	site := siteFake{cfg, engine}
-	_ = Install(plugins, site)
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, site)
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 
 	// Create bindings with site config
 	bindings := liquid.Bindings{"site": site.ToLiquid()}
@@ -51,27 +81,57 @@ func TestGistTagNoscriptDisabled(t *testing.T) {
 }
 
 func TestGistTagNoscriptEnabled(t *testing.T) {
+	serveGists(t)
 	cfg := config.Default()
 	cfg.Set("gist", map[string]interface{}{"noscript": true})
 	engine := liquid.NewEngine()
 	plugins := []string{"jekyll-gist"}
 	site := siteFake{cfg, engine}
-	_ = Install(plugins, site)
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, site)
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 
 	// Create bindings with site config
 	bindings := liquid.Bindings{"site": site.ToLiquid()}
-	s, err := engine.ParseAndRenderString(`{% gist parkr/931c1c8d465a04042403 %}`, bindings)
+	s, err := engine.ParseAndRenderString(`{% gist user/abc hello.rb %}`, bindings)
 	require.NoError(t, err)
+	require.Contains(t, s, `<script src="https://gist.github.com/user/abc.js?file=hello.rb"> </script>`)
+	require.Contains(t, s, `<noscript><pre>puts &#34;&lt;hello&gt;&#34;</pre></noscript>`)
+}
 
-	// Should contain script tag
-	require.Contains(t, s, `<script src="https://gist.github.com/parkr/931c1c8d465a04042403.js"> </script>`)
+func TestGistTagNoscriptCache(t *testing.T) {
+	server, requests := serveGists(t)
+	defer func(enabled bool) {
+		if !enabled {
+			cache.Disable()
+		}
+	}(cache.Enabled())
+	cache.Enable()
 
-	// Should also contain noscript tag (if the gist is accessible)
-	// Note: This test may fail if network is unavailable or gist doesn't exist
-	// In production, the implementation gracefully handles fetch failures
-	if regexp.MustCompile(`<noscript>`).MatchString(s) {
-		require.Contains(t, s, `<noscript><pre>`)
-		require.Contains(t, s, `</pre></noscript>`)
+	engine := liquid.NewEngine()
+	plugins := []string{"jekyll-gist"}
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
+	render := func(src string) string {
+		s, err := engine.ParseAndRenderString(src, liquid.Bindings{})
+		require.NoError(t, err)
+		return s
+	}
+
+	for i := 0; i < 2; i++ {
+		s := render(`{% gist user/abc hello.rb %}`)
+		require.Contains(t, s, `<noscript><pre>puts &#34;&lt;hello&gt;&#34;</pre></noscript>`)
 	}
+	require.Equal(t, 1, *requests, "the content is cached")
+
+	s := render(`{% gist user/abc missing.rb %}`)
+	require.NotContains(t, s, `<noscript>`)
+
+	// offline
+	server.Close()
+	s = render(`{% gist user/def %}`)
+	require.Contains(t, s, `<script src="https://gist.github.com/user/def.js"> </script>`)
+	require.NotContains(t, s, `<noscript>`)
+	require.Contains(t, render(`{% gist user/abc hello.rb %}`), `<noscript>`)
 }
@@ -1,17 +1,20 @@
 package plugins
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/logger"
+	"github.com/osteele/gojekyll/templates"
 	"github.com/osteele/liquid"
 	"golang.org/x/oauth2"
 )
@@ -21,56 +24,18 @@ func init() {
 }
 
 // jekyllGithubMetadataPlugin emulates the jekyll-github-metadata plugin.
+//
+// site.github is read from the local git repository, so that builds work
+// offline and are reproducible. The GitHub API is only used if the
+// github_metadata.api option is set, or (by default) if there's a GitHub
+// token in the environment. The site's github configuration and the
+// _data/github_metadata file override these values.
 type jekyllGithubMetadataPlugin struct{ plugin }
 
 func (p jekyllGithubMetadataPlugin) ModifySiteDrop(s Site, d map[string]interface{}) error {
 	log := logger.Default()
-	var (
-		cfg        = s.Config()
-		isUserPage = false
-		ref        = "master"
-	)
-	nwo, err := getCurrentRepo(cfg)
-	if err != nil {
-		log.Warn("jekyll-github-metadata: can't determine current repository: %s", err)
-		return nil
-	}
-	repo, err := getGitHubRepo(nwo)
-	if err != nil {
-		log.Warn("jekyll-github-metadata: can't fetch repository %q from GitHub: %s", nwo, err)
-		return nil
-	}
-	if *repo.Name == fmt.Sprintf("%s.github.com", strings.ToLower(*repo.Owner.Login)) {
-		isUserPage = true
-		ref = "gh-pages"
-	}
+	cfg := s.Config()
 	gh := map[string]interface{}{
-		"build_revision":     getBuildRevision(cfg.SourceDir()),
-		"clone_url":          repo.CloneURL,
-		"is_project_page":    !isUserPage,
-		"is_user_page":       isUserPage,
-		"issues_url":         repo.IssuesURL,
-		"language":           repo.Language,
-		"latest_release_url": repo.URL,
-		"owner_gravatar_url": repo.Owner.GravatarID,
-		"owner_name":         repo.Owner.Login,
-		"owner_url":          repo.Owner.URL,
-		"project_tagline":    repo.Description,
-		"project_title":      repo.Name, // TODO is this right?
-		"releases_url":       repo.ReleasesURL,
-		"repo_clone_url":     repo.GitURL,
-		"repository_name":    repo.Name,
-		"repository_nwo":     nwo,
-		"repository_url":     repo.URL,
-		"show_downloads?":    repo.HasDownloads,
-		"url":                repo.URL,
-		"tar_url":            repoArchiveURL(repo, "tarball", ref),
-		"zip_url":            repoArchiveURL(repo, "zipball", ref),
-
-		// TODO
-		// contributors public_repositories show_downloads releases versions
-		// wiki_url
-
 		// These may be replaced by environment variable values
 		"api_url":        "https://api.github.com",
 		"environment":    "development",
@@ -83,18 +48,263 @@ func (p jekyllGithubMetadataPlugin) ModifySiteDrop(s Site, d map[string]interfac
 			gh[key] = s
 		}
 	}
+	nwo, err := getCurrentRepo(cfg, fmt.Sprint(gh["hostname"]))
+	if err != nil {
+		log.Warn("jekyll-github-metadata: can't determine current repository: %s", err)
+	}
+	local := gitRepositoryMetadata(cfg.SourceDir())
+	if nwo != "" {
+		mergeGitHubMetadata(local, repositoryMetadata(nwo, gh, local))
+	}
+	if _, ok := gh["build_revision"]; ok {
+		delete(local, "build_revision")
+	}
+	mergeGitHubMetadata(gh, local)
+	if nwo != "" && useGitHubAPI(cfg) {
+		if repo, err := getGitHubRepo(nwo); err != nil {
+			log.Warn("jekyll-github-metadata: can't fetch repository %q from GitHub: %s", nwo, err)
+		} else {
+			mergeGitHubMetadata(gh, apiRepositoryMetadata(repo))
+		}
+	}
+	mergeGitHubMetadata(gh, stringKeyMap(d["github"]))
+	if data, ok := d["data"].(map[string]interface{}); ok {
+		mergeGitHubMetadata(gh, stringKeyMap(data["github_metadata"]))
+	}
 	d["github"] = liquid.IterationKeyedMap(gh)
-	return err
+	return nil
+}
+
+func mergeGitHubMetadata(gh, m map[string]interface{}) {
+	for k, v := range m {
+		gh[k] = v
+	}
+}
+
+// useGitHubAPI returns the github_metadata.api option. If this isn't set,
+// the API is used only if there's a token to authenticate with.
+func useGitHubAPI(cfg *config.Config) bool {
+	options, _ := cfg.Map("github_metadata")
+	return templates.VariableMap(options).Bool("api", githubToken() != "")
+}
+
+func githubToken() string {
+	for _, name := range []string{"JEKYLL_GITHUB_TOKEN", "GITHUB_TOKEN", "OCTOKIT_ACCESS_TOKEN"} {
+		if tok := os.Getenv(name); tok != "" {
+			return tok
+		}
+	}
+	return ""
+}
+
+// repositoryMetadata returns the site.github values that follow from the
+// repository's name and owner. gh supplies the hostnames; local supplies
+// the branch and the tags.
+func repositoryMetadata(nwo string, gh, local map[string]interface{}) map[string]interface{} {
+	nameAndOwner := strings.SplitN(nwo, "/", 2)
+	owner, name := nameAndOwner[0], nameAndOwner[1]
+	var (
+		hostname      = strings.TrimSuffix(fmt.Sprint(gh["hostname"]), "/")
+		pagesHostname = fmt.Sprint(gh["pages_hostname"])
+		repoURL       = hostname + "/" + nwo
+		ownerURL      = hostname + "/" + owner
+		pagesURL      = "https://" + strings.ToLower(owner) + "." + pagesHostname
+		isUserPage    = false
+		ref           = "master"
+	)
+	switch strings.ToLower(name) {
+	case strings.ToLower(owner) + "." + pagesHostname, strings.ToLower(owner) + ".github.com":
+		isUserPage = true
+	default:
+		pagesURL += "/" + name
+	}
+	if source, ok := local["source"].(map[string]interface{}); ok && source["branch"] != "" {
+		ref = fmt.Sprint(source["branch"])
+	}
+	m := map[string]interface{}{
+		"clone_url":          repoURL + ".git",
+		"is_project_page":    !isUserPage,
+		"is_user_page":       isUserPage,
+		"issues_url":         repoURL + "/issues",
+		"owner_gravatar_url": ownerURL + ".png",
+		"owner_name":         owner,
+		"owner_url":          ownerURL,
+		"project_title":      name,
+		"releases_url":       repoURL + "/releases",
+		"repository_name":    name,
+		"repository_nwo":     nwo,
+		"repository_url":     repoURL,
+		"tar_url":            repoURL + "/tarball/" + ref,
+		"url":                pagesURL,
+		"wiki_url":           repoURL + "/wiki",
+		"zip_url":            repoURL + "/zipball/" + ref,
+	}
+	if releases, ok := local["releases"].([]interface{}); ok {
+		for _, r := range releases {
+			r := r.(map[string]interface{})
+			tag := fmt.Sprint(r["tag_name"])
+			r["html_url"] = repoURL + "/releases/tag/" + tag
+			r["tarball_url"] = repoURL + "/tarball/" + tag
+			r["zipball_url"] = repoURL + "/zipball/" + tag
+		}
+		if len(releases) > 0 {
+			m["latest_release_url"] = releases[0].(map[string]interface{})["html_url"]
+		}
+	}
+	return m
+}
+
+// gitRepositoryMetadata returns the site.github values that are read from
+// the git repository that contains dir: the build revision, the branch, the
+// latest commit, the contributors, and the releases (tags). It returns an
+// empty map if dir isn't in a repository, or git isn't installed.
+func gitRepositoryMetadata(dir string) map[string]interface{} {
+	m := map[string]interface{}{}
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return m
+	}
+	path := "/"
+	abs, _ := filepath.Abs(dir)
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		if rel, err := filepath.Rel(top, real); err == nil && rel != "." {
+			path += filepath.ToSlash(rel)
+		}
+	}
+	branch, _ := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if branch == "HEAD" {
+		branch = "" // detached
+	}
+	m["source"] = map[string]interface{}{"branch": branch, "path": path}
+	if out, err := gitOutput(dir, "log", "-1", "--format=%H%x00%aN%x00%aE%x00%ct%x00%s"); err == nil {
+		if fields := strings.Split(out, "\x00"); len(fields) == 5 {
+			m["build_revision"] = fields[0]
+			m["latest_commit"] = map[string]interface{}{
+				"sha":     fields[0],
+				"author":  map[string]interface{}{"name": fields[1], "email": fields[2]},
+				"date":    gitTime(fields[3]),
+				"message": fields[4],
+			}
+		}
+	}
+	m["contributors"] = gitContributors(dir)
+	releases := gitReleases(dir)
+	m["releases"] = releases
+	if len(releases) > 0 {
+		m["latest_release"] = releases[0]
+	}
+	return m
+}
+
+var noreplyEmailMatcher = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// gitContributors returns the authors of the commits on the current branch,
+// by number of commits. The login is known only for GitHub's noreply email
+// addresses.
+func gitContributors(dir string) []interface{} {
+	out, err := gitOutput(dir, "log", "--format=%aN%x00%aE")
+	if err != nil || out == "" {
+		return []interface{}{}
+	}
+	var (
+		counts = map[string]int{}
+		names  = map[string]string{}
+		emails []string
+	)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		email := strings.ToLower(fields[1])
+		if _, seen := counts[email]; !seen {
+			emails = append(emails, email)
+			names[email] = fields[0]
+		}
+		counts[email]++
+	}
+	sort.SliceStable(emails, func(i, j int) bool { return counts[emails[i]] > counts[emails[j]] })
+	contributors := make([]interface{}, 0, len(emails))
+	for _, email := range emails {
+		c := map[string]interface{}{
+			"name":          names[email],
+			"email":         email,
+			"contributions": counts[email],
+		}
+		if m := noreplyEmailMatcher.FindStringSubmatch(email); m != nil {
+			c["login"] = m[1]
+			c["html_url"] = "https://github.com/" + m[1]
+			c["avatar_url"] = "https://github.com/" + m[1] + ".png"
+		}
+		contributors = append(contributors, c)
+	}
+	return contributors
+}
+
+// gitReleases returns the repository's tags as releases, newest first.
+func gitReleases(dir string) []interface{} {
+	out, err := gitOutput(dir, "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)%00%(creatordate:unix)%00%(subject)", "refs/tags")
+	releases := []interface{}{}
+	if err != nil || out == "" {
+		return releases
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		releases = append(releases, map[string]interface{}{
+			"tag_name":     fields[0],
+			"name":         fields[0],
+			"published_at": gitTime(fields[1]),
+			"body":         fields[2],
+		})
+	}
+	return releases
+}
+
+func gitTime(secs string) interface{} {
+	n, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// apiRepositoryMetadata returns the site.github values that only the
+// GitHub API knows.
+func apiRepositoryMetadata(repo *github.Repository) map[string]interface{} {
+	m := map[string]interface{}{
+		"show_downloads":  repo.GetHasDownloads(),
+		"show_downloads?": repo.GetHasDownloads(),
+	}
+	for key, value := range map[string]string{
+		"homepage":           repo.GetHomepage(),
+		"language":           repo.GetLanguage(),
+		"owner_gravatar_url": repo.GetOwner().GetAvatarURL(),
+		"project_tagline":    repo.GetDescription(),
+		"repo_clone_url":     repo.GetGitURL(),
+	} {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	if license := repo.GetLicense(); license != nil {
+		m["license"] = map[string]interface{}{
+			"key":     license.GetKey(),
+			"name":    license.GetName(),
+			"spdx_id": license.GetSPDXID(),
+			"url":     license.GetURL(),
+		}
+	}
+	return m
 }
 
 func getGitHubRepo(nwo string) (*github.Repository, error) {
 	ctx := context.Background()
 	var ts oauth2.TokenSource
-	if tok := os.Getenv("JEKYLL_GITHUB_TOKEN"); tok != "" {
-		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: tok})
-	} else if tok := os.Getenv("GITHUB_TOKEN"); tok != "" {
-		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: tok})
-	} else if tok := os.Getenv("OCTOKIT_ACCESS_TOKEN"); tok != "" {
+	if tok := githubToken(); tok != "" {
 		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: tok})
 	}
 	tc := oauth2.NewClient(ctx, ts)
@@ -104,12 +314,6 @@ func getGitHubRepo(nwo string) (*github.Repository, error) {
 	return repo, err
 }
 
-func repoArchiveURL(repo *github.Repository, format, ref string) string {
-	u := strings.Replace(*repo.ArchiveURL, "{archive_format}", format, 1)
-	u = strings.Replace(u, "{/ref}", "/"+ref, 1)
-	return u
-}
-
 // A map of site.github key -> environment variable name
 var githubPagesEnvVars = map[string]string{
 	"api_url":        "PAGES_API_URL",
@@ -120,34 +324,41 @@ var githubPagesEnvVars = map[string]string{
 	"pages_hostname": "PAGES_PAGES_HOSTNAME",
 }
 
-var githubURLNWOMatcher = regexp.MustCompile(`origin\s+https://github.com/(.+?/.+)\.git\b`).FindSubmatch
+// remoteURLNWO returns the owner/name of a repository from the HTTPS or SSH
+// URL of a git remote on the GitHub server at hostname, such as
+// https://github.com or github.example.com.
+func remoteURLNWO(remoteURL, hostname string) (string, bool) {
+	host := strings.TrimSuffix(hostname, "/")
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	matcher := regexp.MustCompile(`(?:^|[@/])` + regexp.QuoteMeta(host) + `[:/]([^/\s]+/[^/\s]+?)(?:\.git)?/?$`)
+	if m := matcher.FindStringSubmatch(remoteURL); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
 
-func getCurrentRepo(c *config.Config) (string, error) {
-	if nwo := os.Getenv("PAGES_REPO_NWO"); nwo != "" {
-		return nwo, nil
+// getCurrentRepo returns the owner/name of the site's repository, from the
+// PAGES_REPO_NWO environment variable, the repository option, or the
+// origin remote on the GitHub server at hostname.
+func getCurrentRepo(c *config.Config, hostname string) (string, error) {
+	nwo := os.Getenv("PAGES_REPO_NWO")
+	if s, ok := c.String("repository"); ok && nwo == "" {
+		nwo = s
 	}
-	if s, ok := c.String("repository"); ok {
-		return s, nil
+	if nwo != "" {
+		if !strings.Contains(nwo, "/") {
+			return "", fmt.Errorf("%q isn't of the form owner/name", nwo)
+		}
+		return nwo, nil
 	}
-	cmd := exec.Command("git", "remote", "-v")
-	cmd.Dir = c.SourceDir()
-	out, err := cmd.Output()
+	out, err := gitOutput(c.SourceDir(), "remote", "get-url", "origin")
 	if err != nil {
 		return "", err
 	}
-
-	if m := githubURLNWOMatcher(out); m != nil {
-		return string(m[1]), nil
+	if nwo, ok := remoteURLNWO(out, hostname); ok {
+		return nwo, nil
 	}
 	return "", fmt.Errorf("jekyll-github-metadata failed to find current repository")
 }
-
-func getBuildRevision(dir string) string {
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = dir
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return string(bytes.TrimSpace(out))
-}
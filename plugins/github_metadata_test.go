@@ -0,0 +1,119 @@
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestGithubMetadata(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	for _, name := range []string{"PAGES_REPO_NWO", "JEKYLL_BUILD_REVISION", "PAGES_GITHUB_HOSTNAME", "PAGES_PAGES_HOSTNAME",
+		"JEKYLL_GITHUB_TOKEN", "GITHUB_TOKEN", "OCTOKIT_ACCESS_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	dir := t.TempDir()
+	git := func(author string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@users.noreply.github.com",
+			"GIT_COMMITTER_NAME=c", "GIT_COMMITTER_EMAIL=c@example.com",
+			"GIT_AUTHOR_DATE=2020-01-02T03:04:05Z", "GIT_COMMITTER_DATE=2020-01-02T03:04:05Z")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+	git("a", "init", "-q", "-b", "main")
+	git("a", "remote", "add", "origin", "git@github.com:owner/project.git")
+	git("a", "commit", "-q", "--allow-empty", "-m", "first")
+	git("a", "tag", "v1.0")
+	git("b", "commit", "-q", "--allow-empty", "-m", "second")
+	git("a", "commit", "-q", "--allow-empty", "-m", "third")
+	sha := git("a", "rev-parse", "HEAD")
+	sha = sha[:len(sha)-1]
+
+	site := &mockSite{cfg: &config.Config{Source: dir}}
+	drop := func(d map[string]interface{}) map[string]interface{} {
+		require.NoError(t, jekyllGithubMetadataPlugin{}.ModifySiteDrop(site, d))
+		return stringKeyMap(d["github"])
+	}
+	gh := drop(map[string]interface{}{})
+	require.Equal(t, "owner/project", gh["repository_nwo"])
+	require.Equal(t, "https://github.com/owner/project", gh["repository_url"])
+	require.Equal(t, "https://owner.github.io/project", gh["url"])
+	require.Equal(t, true, gh["is_project_page"])
+	require.Equal(t, "https://github.com/owner/project/zipball/main", gh["zip_url"])
+	require.Equal(t, sha, gh["build_revision"])
+	require.Equal(t, map[string]interface{}{"branch": "main", "path": "/"}, gh["source"])
+	require.Equal(t, "third", mapField(gh["latest_commit"], "message"))
+	contributors := gh["contributors"].([]interface{})
+	require.Len(t, contributors, 2)
+	require.Equal(t, "a", mapField(contributors[0], "login"))
+	require.Equal(t, 2, mapField(contributors[0], "contributions"))
+	releases := gh["releases"].([]interface{})
+	require.Len(t, releases, 1)
+	require.Equal(t, "v1.0", mapField(releases[0], "tag_name"))
+	require.Equal(t, "https://github.com/owner/project/releases/tag/v1.0", mapField(releases[0], "html_url"))
+	require.True(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Equal(mapField(releases[0], "published_at").(time.Time)))
+
+	// the site configuration, then the data file, override these
+	gh = drop(map[string]interface{}{
+		"github": yaml.MapSlice{{Key: "project_title", Value: "Configured"}, {Key: "language", Value: "Go"}},
+		"data": map[string]interface{}{
+			"github_metadata": yaml.MapSlice{{Key: "project_title", Value: "Fixture"}},
+		},
+	})
+	require.Equal(t, "Fixture", gh["project_title"])
+	require.Equal(t, "Go", gh["language"])
+
+	// the origin remote is on the configured GitHub server
+	git("a", "remote", "set-url", "origin", "https://github.example.com/team/site.git")
+	require.NotContains(t, drop(map[string]interface{}{}), "repository_nwo")
+	t.Setenv("PAGES_GITHUB_HOSTNAME", "https://github.example.com")
+	gh = drop(map[string]interface{}{})
+	require.Equal(t, "team/site", gh["repository_nwo"])
+	require.Equal(t, "https://github.example.com/team/site", gh["repository_url"])
+
+	t.Setenv("PAGES_REPO_NWO", "owner/owner.github.io")
+	t.Setenv("JEKYLL_BUILD_REVISION", "abc123")
+	gh = drop(map[string]interface{}{})
+	require.Equal(t, true, gh["is_user_page"])
+	require.Equal(t, "https://owner.github.io", gh["url"])
+	require.Equal(t, "abc123", gh["build_revision"])
+}
+
+func TestRemoteURLNWO(t *testing.T) {
+	tests := []struct{ url, hostname, nwo string }{
+		{"https://github.com/owner/project.git", "https://github.com", "owner/project"},
+		{"https://github.com/owner/project/", "https://github.com", "owner/project"},
+		{"git@github.com:owner/project.git", "https://github.com", "owner/project"},
+		{"ssh://git@github.example.com/owner/project", "github.example.com", "owner/project"},
+		{"git@github.example.com:owner/project.git", "https://github.example.com/", "owner/project"},
+		{"git@github.example.com:owner/project.git", "https://github.com", ""},
+		{"https://notgithub.com/owner/project.git", "https://github.com", ""},
+		{"https://gitlab.com/owner/project.git", "https://github.com", ""},
+	}
+	for _, test := range tests {
+		nwo, ok := remoteURLNWO(test.url, test.hostname)
+		require.Equal(t, test.nwo != "", ok, test.url)
+		require.Equal(t, test.nwo, nwo, test.url)
+	}
+}
+
+func TestGithubMetadata_notRepository(t *testing.T) {
+	t.Setenv("PAGES_REPO_NWO", "")
+	site := &mockSite{cfg: &config.Config{Source: t.TempDir()}}
+	d := map[string]interface{}{}
+	require.NoError(t, jekyllGithubMetadataPlugin{}.ModifySiteDrop(site, d))
+	gh := stringKeyMap(d["github"])
+	require.Equal(t, "https://github.com", gh["hostname"])
+	require.NotContains(t, gh, "repository_nwo")
+}
@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kyokomi/emoji"
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// jemojiPlugin emulates the jemoji plugin.
+type jemojiPlugin struct {
+	plugin
+	site Site
+}
+
+func init() {
+	register("jemoji", &jemojiPlugin{})
+}
+
+// defaultEmojiSource is the asset host that jemoji uses by default.
+const defaultEmojiSource = "https://github.githubassets.com/images/icons/emoji/"
+
+// emojiShortcodeMatcher matches the :shortcode: syntax.
+var emojiShortcodeMatcher = regexp.MustCompile(`:[\w+-]+:`)
+
+func (p *jemojiPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	return nil
+}
+
+// PostRender replaces emoji shortcodes, outside of code and pre elements,
+// by emoji images as jemoji does; or by Unicode characters, if the emoji.mode
+// option is "unicode".
+//
+// The emoji.src option sets the URL of the emoji images. If this is a site
+// path, such as /assets/emoji/, the images are read from the site source, and
+// an emoji without an image is written as a Unicode character.
+func (p *jemojiPlugin) PostRender(_ Page, b []byte) ([]byte, error) {
+	cfg := p.site.Config()
+	m, _ := cfg.Map("emoji")
+	options := templates.VariableMap(m)
+	unicode := options.String("mode", "image") == "unicode"
+	src := options.String("src", defaultEmojiSource)
+	if !strings.HasSuffix(src, "/") {
+		src += "/"
+	}
+	local := !strings.Contains(src, "://") && !strings.HasPrefix(src, "//")
+	codes := emoji.CodeMap()
+	replace := func(code string) string {
+		char, ok := codes[code]
+		if !ok {
+			return code
+		}
+		if unicode {
+			return char
+		}
+		filename := emojiImageFilename(char)
+		url := src + filename
+		if local {
+			if _, err := os.Stat(filepath.Join(cfg.SourceDir(), filepath.FromSlash(src), filename)); err != nil {
+				return char
+			}
+			url = utils.URLJoin(cfg.BaseURL, url)
+		}
+		code = html.EscapeString(code)
+		return fmt.Sprintf(`<img class="emoji" title="%s" alt="%s" src="%s" height="20" width="20">`, code, code, html.EscapeString(url))
+	}
+	return utils.ReplaceHTMLText(b, []string{"code", "pre", "tt"}, func(s string) string {
+		return emojiShortcodeMatcher.ReplaceAllStringFunc(s, replace)
+	}), nil
+}
+
+// emojiImageFilename returns the path of an emoji's image, relative to the
+// asset host: its code points in hex, without variation selectors.
+func emojiImageFilename(char string) string {
+	var hex []string
+	for _, r := range char {
+		if r != '\ufe0f' {
+			hex = append(hex, fmt.Sprintf("%04x", r))
+		}
+	}
+	return "unicode/" + strings.Join(hex, "-") + ".png"
+}
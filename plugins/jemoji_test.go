@@ -0,0 +1,44 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJemoji(t *testing.T) {
+	plugin := jemojiPlugin{}
+	emojify := func(cfg config.Config, doc string) string {
+		require.NoError(t, plugin.AfterInitSite(siteFake{cfg, nil}))
+		b, err := plugin.PostRender(nil, []byte(doc))
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	cfg := config.FromString("{}")
+	require.Equal(t,
+		`<body title=":+1:"><p>Hi <img class="emoji" title=":+1:" alt=":+1:" src="https://github.githubassets.com/images/icons/emoji/unicode/1f44d.png" height="20" width="20"> :nope:</p><code>:+1:</code></body>`,
+		emojify(cfg, `<body title=":+1:"><p>Hi :+1: :nope:</p><code>:+1:</code></body>`))
+	require.Contains(t, emojify(cfg, `<body>:heart:</body>`), `src="https://github.githubassets.com/images/icons/emoji/unicode/2764.png"`)
+	require.Equal(t, "<p>:+1:</p>", emojify(cfg, "<p>:+1:</p>"))
+
+	cfg = config.FromString("emoji:\n  mode: unicode")
+	require.Equal(t, "<body>👍<pre>:+1:</pre></body>", emojify(cfg, "<body>:+1:<pre>:+1:</pre></body>"))
+
+	cfg = config.FromString("emoji:\n  src: https://cdn.example.com/emoji")
+	require.Contains(t, emojify(cfg, "<body>:+1:</body>"), `src="https://cdn.example.com/emoji/unicode/1f44d.png"`)
+
+	// a local directory, with an image for only one of the emoji
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets/emoji/unicode"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets/emoji/unicode/1f44d.png"), nil, 0644))
+	cfg = config.FromString("emoji:\n  src: /assets/emoji/")
+	cfg.Source = dir
+	cfg.BaseURL = "/blog"
+	require.Equal(t,
+		`<body><img class="emoji" title=":+1:" alt=":+1:" src="/blog/assets/emoji/unicode/1f44d.png" height="20" width="20"> 😄</body>`,
+		emojify(cfg, "<body>:+1: :smile:</body>"))
+}
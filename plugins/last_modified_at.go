@@ -0,0 +1,152 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/render"
+	"github.com/osteele/tuesday"
+)
+
+type jekyllLastModifiedAtPlugin struct {
+	plugin
+	site Site
+
+	// the commit times of the site's repository, looked up once per build
+	commitTimesOnce sync.Once
+	commitTimes     map[string]time.Time
+}
+
+func init() {
+	register("jekyll-last-modified-at", &jekyllLastModifiedAtPlugin{})
+}
+
+func (p *jekyllLastModifiedAtPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	return nil
+}
+
+func (p *jekyllLastModifiedAtPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
+	e.RegisterTag("last_modified_at", p.lastModifiedAtTag)
+	return nil
+}
+
+// PostInitPage sets page.last_modified_at to the time of the last commit
+// that changed the page's source file, or to the file's modification time
+// if git doesn't track it. It doesn't replace a value from the front matter.
+func (p *jekyllLastModifiedAtPlugin) PostInitPage(s Site, pg Page) error {
+	fm := pg.FrontMatter()
+	if _, ok := fm["last_modified_at"]; ok || pg.Source() == "" {
+		return nil
+	}
+	filename, err := filepath.Abs(pg.Source())
+	if err != nil {
+		return err
+	}
+	// git reports paths within the repository's real directory
+	if real, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = real
+	}
+	p.commitTimesOnce.Do(func() {
+		p.commitTimes = gitCommitTimes(s.Config().SourceDir())
+	})
+	if t, ok := p.commitTimes[filename]; ok {
+		fm["last_modified_at"] = t
+	} else if info, err := os.Stat(filename); err == nil {
+		fm["last_modified_at"] = info.ModTime()
+	}
+	return nil
+}
+
+// lastModifiedAtTag renders {% last_modified_at %}, the page's modification
+// time. An optional argument is a strftime format; the default is the
+// last_modified_at.date_format option, or "%d-%b-%y".
+func (p *jekyllLastModifiedAtPlugin) lastModifiedAtTag(ctx render.Context) (string, error) {
+	format, err := ctx.ExpandTagArg()
+	if err != nil {
+		return "", err
+	}
+	format = strings.TrimSpace(format)
+	if format == "" {
+		options, _ := p.site.Config().Map("last_modified_at")
+		format = templates.VariableMap(options).String("date_format", "%d-%b-%y")
+	}
+	value, err := ctx.EvaluateString("page.last_modified_at")
+	if err != nil {
+		return "", err
+	}
+	t, ok := value.(time.Time)
+	if !ok {
+		return "", nil
+	}
+	return tuesday.Strftime(format, t)
+}
+
+// gitHistory caches the commit times of a repository's files, so that
+// rebuilds don't scan the history again unless HEAD has changed.
+// Within a build, the plugin instance keeps the times that this returns.
+var gitHistory struct {
+	sync.Mutex
+	dir, head string
+	times     map[string]time.Time
+}
+
+// gitCommitTimes returns a map from the absolute paths of the files in the
+// git repository that contains dir, to the times of their latest commits.
+// It returns nil if dir isn't in a repository, or git isn't installed.
+func gitCommitTimes(dir string) map[string]time.Time {
+	gitHistory.Lock()
+	defer gitHistory.Unlock()
+	top, err := gitOutput(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	head, err := gitOutput(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil
+	}
+	if gitHistory.dir == top && gitHistory.head == head {
+		return gitHistory.times
+	}
+	// Commits are newest first, so a file's first appearance is its
+	// latest commit.
+	out, err := gitOutput(top, "-c", "core.quotePath=false", "log", "--format=%x00%ct", "--name-only", "--no-renames")
+	if err != nil {
+		return nil
+	}
+	times := map[string]time.Time{}
+	var commitTime time.Time
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\x00"):
+			if secs, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				commitTime = time.Unix(secs, 0)
+			}
+		case line != "":
+			filename := filepath.Join(top, filepath.FromSlash(line))
+			if _, seen := times[filename]; !seen {
+				times[filename] = commitTime
+			}
+		}
+	}
+	gitHistory.dir, gitHistory.head, gitHistory.times = top, head, times
+	return times
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return string(bytes.TrimSpace(out)), err
+}
@@ -0,0 +1,64 @@
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastModifiedAt(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir := t.TempDir()
+	commitDate := "2020-01-02T03:04:05Z"
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com",
+			"GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com",
+			"GIT_COMMITTER_DATE="+commitDate)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	for _, name := range []string{"committed.md", "untracked.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("text"), 0644))
+	}
+	git("init", "-q")
+	git("add", "committed.md")
+	git("commit", "-q", "-m", "add")
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "untracked.md"), mtime, mtime))
+
+	plugin := &jekyllLastModifiedAtPlugin{}
+	site := &mockSite{cfg: &config.Config{Source: dir}}
+	lastModified := func(name string, fm pages.FrontMatter) interface{} {
+		pg := &rawMockPage{mockPage: mockPage{fm: fm}, source: filepath.Join(dir, name)}
+		require.NoError(t, plugin.PostInitPage(site, pg))
+		return pg.FrontMatter()["last_modified_at"]
+	}
+
+	committed := lastModified("committed.md", nil)
+	require.IsType(t, time.Time{}, committed)
+	require.True(t, committed.(time.Time).Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	untracked := lastModified("untracked.md", nil)
+	require.IsType(t, time.Time{}, untracked)
+	require.True(t, untracked.(time.Time).Equal(mtime))
+	require.Equal(t, "declared", lastModified("committed.md", pages.FrontMatter{"last_modified_at": "declared"}))
+
+	// the repository is read once per build; the next build's instance
+	// sees the new commit
+	commitDate = "2022-03-04T05:06:07Z"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "committed.md"), []byte("changed"), 0644))
+	git("commit", "-q", "-am", "change")
+	require.True(t, lastModified("committed.md", nil).(time.Time).Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	plugin = &jekyllLastModifiedAtPlugin{}
+	require.True(t, lastModified("committed.md", nil).(time.Time).Equal(time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)))
+}
@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+)
+
+// jekyllMentionsPlugin emulates the jekyll-mentions plugin.
+type jekyllMentionsPlugin struct {
+	plugin
+	site Site
+}
+
+func init() {
+	register("jekyll-mentions", &jekyllMentionsPlugin{})
+}
+
+const defaultMentionsBaseURL = "https://github.com"
+
+// mentionMatcher matches @username, where it doesn't follow a word
+// character, as in an email address. mentionTarget checks the text after
+// it, which RE2 can't look ahead at.
+var mentionMatcher = regexp.MustCompile(`(?i)(?:^|[^\w@])@([a-z0-9][a-z0-9-]*)`)
+
+func (p *jekyllMentionsPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	return nil
+}
+
+// PostRender links @mentions to the user's page at the base URL that the
+// jekyll-mentions option sets, except within code, pre, and a elements, and
+// on pages whose jekyll-mentions front matter variable is false.
+func (p *jekyllMentionsPlugin) PostRender(pg Page, b []byte) ([]byte, error) {
+	if pg != nil {
+		if enabled, ok := pg.FrontMatter()["jekyll-mentions"].(bool); ok && !enabled {
+			return b, nil
+		}
+	}
+	base := strings.TrimSuffix(mentionsBaseURL(p.site), "/")
+	return utils.ReplaceHTMLText(b, []string{"a", "code", "pre", "tt"}, func(s string) string {
+		out := new(strings.Builder)
+		prev := 0
+		for _, m := range mentionMatcher.FindAllStringSubmatchIndex(s, -1) {
+			start, end, name := m[2]-1, m[3], s[m[2]:m[3]]
+			if !mentionTarget(s[end:]) {
+				continue
+			}
+			out.WriteString(s[prev:start])
+			fmt.Fprintf(out, `<a href="%s/%s" class="user-mention">@%s</a>`, html.EscapeString(base), name, name)
+			prev = end
+		}
+		out.WriteString(s[prev:])
+		return out.String()
+	}), nil
+}
+
+// mentionTarget returns true if the text that follows a username ends it:
+// a username isn't followed by a slash, as in @scope/package, or by more
+// name characters after a period, as in @example.com.
+func mentionTarget(rest string) bool {
+	trimmed := strings.TrimLeft(rest, ".")
+	switch {
+	case rest == "":
+		return true
+	case rest[0] == '/' || rest[0] == '_':
+		return false
+	case trimmed == "":
+		return true
+	case len(trimmed) < len(rest):
+		// periods followed by a name character continue a domain name
+		c := trimmed[0]
+		return !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z')
+	}
+	return true
+}
+
+// mentionsBaseURL returns the jekyll-mentions option's base URL. The option
+// can be the URL, or a map with a base_url key. For compatibility with
+// older configurations, the mentions key is also read.
+func mentionsBaseURL(s Site) string {
+	cfg := s.Config()
+	for _, key := range []string{"jekyll-mentions", "mentions"} {
+		if url, ok := cfg.String(key); ok {
+			return url
+		}
+		if m, ok := cfg.Map(key); ok {
+			if url, ok := m["base_url"].(string); ok {
+				return url
+			}
+		}
+	}
+	return defaultMentionsBaseURL
+}
@@ -0,0 +1,44 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJekyllMentions(t *testing.T) {
+	plugin := jekyllMentionsPlugin{}
+	mention := func(cfg config.Config, pg Page, doc string) string {
+		require.NoError(t, plugin.AfterInitSite(siteFake{cfg, nil}))
+		b, err := plugin.PostRender(pg, []byte("<body>"+doc+"</body>"))
+		require.NoError(t, err)
+		return string(b)
+	}
+	link := func(base, name string) string {
+		return `<a href="` + base + "/" + name + `" class="user-mention">@` + name + `</a>`
+	}
+
+	cfg := config.FromString("{}")
+	require.Equal(t, "<body>Hi "+link("https://github.com", "osteele")+", "+link("https://github.com", "a-b")+".</body>",
+		mention(cfg, nil, "Hi @osteele, @a-b."))
+	for _, doc := range []string{
+		"me@example.com",
+		"@scope/package",
+		"@example.com",
+		"<code>@osteele</code>",
+		"<pre>@osteele</pre>",
+		`<a href="/">@osteele</a>`,
+	} {
+		require.Equal(t, "<body>"+doc+"</body>", mention(cfg, nil, doc), doc)
+	}
+
+	cfg = config.FromString("jekyll-mentions:\n  base_url: https://github.example.com/")
+	require.Equal(t, "<body>"+link("https://github.example.com", "me")+"</body>", mention(cfg, nil, "@me"))
+	cfg = config.FromString("jekyll-mentions: https://twitter.com")
+	require.Equal(t, "<body>"+link("https://twitter.com", "me")+"</body>", mention(cfg, nil, "@me"))
+
+	pg := &mockPage{fm: pages.FrontMatter{"jekyll-mentions": false}}
+	require.Equal(t, "<body>@me</body>", mention(cfg, pg, "@me"))
+}
@@ -0,0 +1,375 @@
+package plugins
+
+import (
+	"bytes"
+	"crypto/md5"
+	"fmt"
+	"html"
+	"image"
+	_ "image/gif" // register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/logger"
+	"github.com/osteele/gojekyll/tags"
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/gojekyll/utils"
+	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/render"
+	"golang.org/x/image/draw"
+)
+
+// jekyllPictureTagPlugin emulates the jekyll_picture_tag plugin.
+//
+// The {% picture %} and {% img %} tags read an image from the site source,
+// generate resized variants, and add them to the site as generated files.
+// Variants are cached by the content of the source image.
+type jekyllPictureTagPlugin struct {
+	plugin
+	site Site
+
+	sourcesMx sync.Mutex
+	sources   map[string]pictureSource // by filename
+
+	warnOnce sync.Once
+}
+
+// A pictureSource is the hash and dimensions of a source image, as of its
+// modification time.
+type pictureSource struct {
+	modTime time.Time
+	hash    string // hex MD5 of the content
+	config  image.Config
+	format  string
+}
+
+func init() {
+	register("jekyll_picture_tag", &jekyllPictureTagPlugin{})
+}
+
+func (p *jekyllPictureTagPlugin) AfterInitSite(s Site) error {
+	p.site = s
+	return nil
+}
+
+func (p *jekyllPictureTagPlugin) ConfigureTemplateEngine(e *liquid.Engine) error {
+	e.RegisterTag("picture", p.pictureTag)
+	e.RegisterTag("img", p.imgTag)
+	return nil
+}
+
+// Defaults for the `picture` configuration map.
+var (
+	defaultPictureWidths  = []int{400, 800, 1200}
+	defaultPictureFormats = []string{"original"}
+)
+
+const (
+	defaultPictureOutput  = "generated"
+	defaultPictureQuality = 85
+)
+
+// pictureConfig is the `picture` section of the site configuration.
+type pictureConfig struct {
+	source  string // directory, relative to the site source, that image paths are relative to
+	output  string // directory, relative to the site destination, that variants are written to
+	widths  []int
+	formats []string
+	sizes   string
+	quality int
+}
+
+func (p *jekyllPictureTagPlugin) config() pictureConfig {
+	pc := pictureConfig{
+		output:  defaultPictureOutput,
+		widths:  defaultPictureWidths,
+		formats: defaultPictureFormats,
+		quality: defaultPictureQuality,
+	}
+	m, ok := p.site.Config().Map("picture")
+	if !ok {
+		return pc
+	}
+	vm := templates.VariableMap(m)
+	pc.source = vm.String("source", pc.source)
+	pc.output = vm.String("output", pc.output)
+	pc.sizes = vm.String("sizes", pc.sizes)
+	if n, ok := m["quality"].(int); ok {
+		pc.quality = n
+	}
+	if ws, ok := m["widths"].([]interface{}); ok {
+		pc.widths = nil
+		for _, w := range ws {
+			if n, ok := w.(int); ok && n > 0 {
+				pc.widths = append(pc.widths, n)
+			}
+		}
+	}
+	if fs, ok := m["formats"].([]interface{}); ok {
+		pc.formats = nil
+		for _, f := range fs {
+			pc.formats = append(pc.formats, fmt.Sprint(f))
+		}
+	}
+	return pc
+}
+
+func (p *jekyllPictureTagPlugin) pictureTag(ctx render.Context) (string, error) {
+	return p.renderTag(ctx, true)
+}
+
+func (p *jekyllPictureTagPlugin) imgTag(ctx render.Context) (string, error) {
+	return p.renderTag(ctx, false)
+}
+
+func (p *jekyllPictureTagPlugin) renderTag(ctx render.Context, picture bool) (string, error) {
+	argsline, err := ctx.ExpandTagArg()
+	if err != nil {
+		return "", err
+	}
+	args, err := tags.ParseArgs(argsline)
+	if err != nil {
+		return "", err
+	}
+	if len(args.Args) != 1 {
+		return "", fmt.Errorf("%s tag: expected an image path", ctx.TagName())
+	}
+	options, err := args.EvalOptions(ctx)
+	if err != nil {
+		return "", err
+	}
+	pc := p.config()
+	if s, ok := options["sizes"]; ok {
+		pc.sizes = fmt.Sprint(s)
+		delete(options, "sizes")
+	}
+	sets, err := p.makeSourceSets(pc, args.Args[0])
+	if err != nil {
+		return "", fmt.Errorf("%s tag: %s", ctx.TagName(), err)
+	}
+	return pictureMarkup(sets, options, pc.sizes, picture), nil
+}
+
+// A pictureVariant is a resized image.
+type pictureVariant struct {
+	url           string
+	width, height int
+}
+
+// A pictureSourceSet is a list of variants of the same image, in a single format.
+type pictureSourceSet struct {
+	mimeType string
+	variants []pictureVariant // in order of increasing width
+}
+
+func (s pictureSourceSet) srcset() string {
+	items := make([]string, len(s.variants))
+	for i, v := range s.variants {
+		items[i] = fmt.Sprintf("%s %dw", v.url, v.width)
+	}
+	return strings.Join(items, ", ")
+}
+
+func (s pictureSourceSet) largest() pictureVariant {
+	return s.variants[len(s.variants)-1]
+}
+
+// makeSourceSets returns one source set per output format. The last source
+// set is in the format of the original image, and serves as the fallback.
+func (p *jekyllPictureTagPlugin) makeSourceSets(pc pictureConfig, rel string) ([]pictureSourceSet, error) {
+	var (
+		cfg      = p.site.Config()
+		filename = filepath.Join(cfg.SourceDir(), pc.source, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+	)
+	if r, err := filepath.Rel(cfg.SourceDir(), filename); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s: the image isn't in the site source directory", rel)
+	}
+	src, err := p.readSource(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", rel, err)
+	}
+	ic, format := src.config, src.format
+	if ic.Width <= 0 || ic.Height <= 0 {
+		return nil, fmt.Errorf("%s: image has no width or height", rel)
+	}
+	// The content is read only if a variant isn't in the cache.
+	var content []byte
+	resize := func(w, h int, f string) (string, error) {
+		if content == nil {
+			b, err := os.ReadFile(filename)
+			if err != nil {
+				return "", err
+			}
+			content = b
+		}
+		return resizeImage(content, w, h, f, pc.quality)
+	}
+	var (
+		hash   = src.hash[:8]
+		base   = strings.TrimSuffix(path.Base(filepath.ToSlash(rel)), path.Ext(rel))
+		widths = pictureWidths(pc.widths, ic.Width)
+		sets   []pictureSourceSet
+	)
+	// The original format comes last, so that it's the fallback.
+	var formats []string
+	for _, f := range pc.formats {
+		if f == "jpg" {
+			f = "jpeg"
+		}
+		if f != "original" && f != format && !utils.StringArrayContains(formats, f) {
+			formats = append(formats, f)
+		}
+	}
+	for _, f := range append(formats, format) {
+		mimeType, ext, ok := pictureFormat(f)
+		if !ok {
+			p.warnOnce.Do(func() {
+				logger.Default().Warn("jekyll_picture_tag: gojekyll can't write %s images; skipping this format.", f)
+			})
+			continue
+		}
+		set := pictureSourceSet{mimeType: mimeType}
+		for _, w := range widths {
+			h := (ic.Height*w + ic.Width/2) / ic.Width
+			url := path.Join("/", pc.output, fmt.Sprintf("%s-%d-%s%s", base, w, hash, ext))
+			b, err := cache.WithFile(fmt.Sprintf("picture %s %dx%d q=%d", f, w, h, pc.quality), src.hash, func() (string, error) {
+				return resize(w, h, f)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", rel, err)
+			}
+			p.site.AddGeneratedFile(url, []byte(b))
+			set.variants = append(set.variants, pictureVariant{cfg.BaseURL + url, w, h})
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// readSource returns the hash and dimensions of an image. These are read
+// once per build, and again only if the file is modified.
+func (p *jekyllPictureTagPlugin) readSource(filename string) (pictureSource, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return pictureSource{}, err
+	}
+	p.sourcesMx.Lock()
+	defer p.sourcesMx.Unlock()
+	if s, ok := p.sources[filename]; ok && s.modTime.Equal(info.ModTime()) {
+		return s, nil
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return pictureSource{}, err
+	}
+	ic, format, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return pictureSource{}, err
+	}
+	sum := md5.Sum(b)
+	s := pictureSource{info.ModTime(), fmt.Sprintf("%x", sum), ic, format}
+	if p.sources == nil {
+		p.sources = map[string]pictureSource{}
+	}
+	p.sources[filename] = s
+	return s, nil
+}
+
+// pictureWidths returns the configured widths that don't exceed the image
+// width, in increasing order. If none do, it returns the image width.
+func pictureWidths(configured []int, imageWidth int) []int {
+	var widths []int
+	for _, w := range configured {
+		if w <= imageWidth {
+			widths = append(widths, w)
+		}
+	}
+	if len(widths) == 0 {
+		widths = []int{imageWidth}
+	}
+	sort.Ints(widths)
+	return widths
+}
+
+// pictureFormat returns the MIME type and file extension for an image
+// format, and whether gojekyll can encode it.
+func pictureFormat(format string) (mimeType, ext string, ok bool) {
+	switch format {
+	case "jpeg":
+		return "image/jpeg", ".jpg", true
+	case "png":
+		return "image/png", ".png", true
+	case "gif":
+		// Re-encode resized GIFs as PNG, since animation is lost anyway.
+		return "image/png", ".png", true
+	default:
+		// There's no pure-Go WebP or AVIF encoder.
+		return "", "", false
+	}
+}
+
+func resizeImage(src []byte, width, height int, format string, quality int) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return "", err
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+	buf := new(bytes.Buffer)
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buf, dst, &jpeg.Options{Quality: quality})
+	default:
+		err = png.Encode(buf, dst)
+	}
+	return buf.String(), err
+}
+
+// pictureMarkup returns a <picture> element, or an <img> element with a
+// srcset attribute in the fallback format if picture is false.
+func pictureMarkup(sets []pictureSourceSet, attrs map[string]interface{}, sizes string, picture bool) string {
+	var (
+		buf      = new(bytes.Buffer)
+		fallback = sets[len(sets)-1]
+		largest  = fallback.largest()
+	)
+	if picture {
+		buf.WriteString("<picture>")
+		for _, s := range sets[:len(sets)-1] {
+			fmt.Fprintf(buf, `<source type="%s" srcset="%s"`, s.mimeType, html.EscapeString(s.srcset()))
+			if sizes != "" {
+				fmt.Fprintf(buf, ` sizes="%s"`, html.EscapeString(sizes))
+			}
+			buf.WriteString(">")
+		}
+	}
+	fmt.Fprintf(buf, `<img src="%s" srcset="%s"`, html.EscapeString(largest.url), html.EscapeString(fallback.srcset()))
+	if sizes != "" {
+		fmt.Fprintf(buf, ` sizes="%s"`, html.EscapeString(sizes))
+	}
+	fmt.Fprintf(buf, ` width="%d" height="%d"`, largest.width, largest.height)
+	if _, ok := attrs["alt"]; !ok {
+		attrs["alt"] = ""
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, ` %s="%s"`, k, html.EscapeString(fmt.Sprint(attrs[k])))
+	}
+	buf.WriteString(">")
+	if picture {
+		buf.WriteString("</picture>")
+	}
+	return buf.String()
+}
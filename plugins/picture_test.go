@@ -0,0 +1,89 @@
+package plugins
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/liquid"
+	"github.com/stretchr/testify/require"
+)
+
+type pictureSiteFake struct {
+	siteFake
+	files map[string][]byte
+}
+
+func (s pictureSiteFake) AddGeneratedFile(url string, b []byte) { s.files[url] = b }
+
+func writeTestPNG(t *testing.T, filename string, width, height int) {
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	require.NoError(t, png.Encode(f, image.NewRGBA(image.Rect(0, 0, width, height))))
+}
+
+func TestPictureTag(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	writeTestPNG(t, filepath.Join(dir, "cat.png"), 1000, 500)
+	writeTestPNG(t, filepath.Join(dir, "..", "outside.png"), 100, 100)
+	cfg := config.Default()
+	cfg.Source = dir
+	cfg.Set("picture", map[string]interface{}{
+		"widths":  []interface{}{400, 800, 1600},
+		"formats": []interface{}{"webp", "original"},
+	})
+	engine := liquid.NewEngine()
+	site := pictureSiteFake{siteFake{cfg, engine}, map[string][]byte{}}
+	p := &jekyllPictureTagPlugin{}
+	require.NoError(t, p.AfterInitSite(site))
+	require.NoError(t, p.ConfigureTemplateEngine(engine))
+
+	s, err := engine.ParseAndRenderString(`{% picture cat.png alt="A cat" %}`, liquid.Bindings{})
+	require.NoError(t, err)
+	require.Contains(t, s, `<picture><img src="/generated/cat-800-`)
+	require.Contains(t, s, `400w, /generated/cat-800-`)
+	require.Contains(t, s, `width="800" height="400" alt="A cat"></picture>`)
+	require.Len(t, site.files, 2)
+	for url, b := range site.files {
+		img, err := png.Decode(bytes.NewReader(b))
+		require.NoError(t, err, url)
+		require.Contains(t, []int{400, 800}, img.Bounds().Dx())
+	}
+
+	s, err = engine.ParseAndRenderString(`{% img cat.png sizes="50vw" %}`, liquid.Bindings{})
+	require.NoError(t, err)
+	require.NotContains(t, s, `<picture>`)
+	require.Contains(t, s, `sizes="50vw"`)
+
+	_, err = engine.ParseAndRenderString(`{% picture missing.png %}`, liquid.Bindings{})
+	require.Error(t, err)
+	_, err = engine.ParseAndRenderString(`{% picture ../outside.png %}`, liquid.Bindings{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "isn't in the site source directory")
+
+	// the image is read again only if it's modified
+	require.Len(t, p.sources, 1)
+	writeTestPNG(t, filepath.Join(dir, "cat.png"), 300, 200)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "cat.png"), later, later))
+	s, err = engine.ParseAndRenderString(`{% img cat.png %}`, liquid.Bindings{})
+	require.NoError(t, err)
+	require.Contains(t, s, `width="300" height="200"`)
+
+	// a GIF header with a width of zero
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.gif"), []byte("GIF89a\x00\x00\x01\x00\x00\x00\x00;"), 0644))
+	_, err = engine.ParseAndRenderString(`{% picture empty.gif %}`, liquid.Bindings{})
+	require.Error(t, err)
+}
+
+func TestPictureWidths(t *testing.T) {
+	require.Equal(t, []int{400, 800}, pictureWidths([]int{800, 400, 1200}, 1000))
+	require.Equal(t, []int{300}, pictureWidths([]int{400, 800}, 300))
+}
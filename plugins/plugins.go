@@ -6,15 +6,16 @@
 package plugins
 
 import (
-	"regexp"
+	"fmt"
+	"reflect"
 	"sort"
 
-	"github.com/kyokomi/emoji"
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/logger"
 	"github.com/osteele/gojekyll/pages"
-	"github.com/osteele/gojekyll/utils"
 	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/tags"
+	"gopkg.in/yaml.v2"
 )
 
 // Plugin describes the hooks that a plugin can override.
@@ -25,11 +26,12 @@ type Plugin interface {
 	ModifySiteDrop(Site, map[string]interface{}) error
 	PostInitPage(Site, Page) error
 	PostReadSite(Site) error
-	PostRender([]byte) ([]byte, error)
+	PostRender(Page, []byte) ([]byte, error)
 }
 
 // Site is the site interface that is available to plugins.
 type Site interface {
+	AddGeneratedFile(url string, content []byte)
 	AddHTMLPage(url string, tpl string, fm pages.FrontMatter)
 	Config() *config.Config
 	TemplateEngine() *liquid.Engine
@@ -43,24 +45,46 @@ type Site interface {
 type Page = pages.Page
 
 // Lookup returns a plugin if it has been registered.
+//
+// This is the registered instance. Install creates the instances that a
+// site uses.
 func Lookup(name string) (Plugin, bool) {
 	p, found := directory[name]
 	return p, found
 }
 
-// Install installs a registered plugin.
-func Install(names []string, site Site) error {
+// Install creates instances of the registered plugins, and calls their
+// AfterInitSite methods. It returns the instances by name.
+//
+// Each site has its own instances, so that a plugin that keeps state, such
+// as the site, doesn't share it with the other sites of a multilingual
+// build.
+func Install(names []string, site Site) (map[string]Plugin, error) {
 	log := logger.Default()
+	installed := map[string]Plugin{}
 	for _, name := range names {
 		if p, found := directory[name]; found {
+			p = newInstance(p)
 			if err := p.AfterInitSite(site); err != nil {
-				return err
+				return nil, err
 			}
+			installed[name] = p
 		} else {
 			log.Warn("gojekyll does not emulate the %s plugin.", name)
 		}
 	}
-	return nil
+	return installed, nil
+}
+
+// newInstance returns a plugin with the registered plugin's type. A plugin
+// whose methods have pointer receivers is a new zero value; other plugins
+// have no state, and are returned as is.
+func newInstance(p Plugin) Plugin {
+	v := reflect.ValueOf(p)
+	if v.Kind() != reflect.Ptr {
+		return p
+	}
+	return reflect.New(v.Elem().Type()).Interface().(Plugin)
 }
 
 // Names returns a sorted list of names of registered plugins.
@@ -84,7 +108,7 @@ func (p plugin) ModifyPluginList(names []string) []string          { return name
 func (p plugin) ModifySiteDrop(Site, map[string]interface{}) error { return nil }
 func (p plugin) PostInitPage(Site, Page) error                     { return nil }
 func (p plugin) PostReadSite(Site) error                           { return nil }
-func (p plugin) PostRender(b []byte) ([]byte, error)               { return b, nil }
+func (p plugin) PostRender(_ Page, b []byte) ([]byte, error)       { return b, nil }
 
 var directory = map[string]Plugin{}
 
@@ -98,38 +122,17 @@ func register(name string, p Plugin) {
 // Add the built-in plugins defined in this file.
 // More extensive plugins are defined and registered in own files.
 func init() {
-	register("jemoji", jemojiPlugin{})
-	register("jekyll-mentions", jekyllMentionsPlugin{})
 	register("jekyll-optional-front-matter", jekyllOptionalFrontMatterPlugin{})
 
 	// Gojekyll behaves as though the following plugins are always loaded.
 	// Define them here so we don't see warnings that they aren't defined.
+	register("jekyll-include-cache", plugin{})
 	register("jekyll-live-reload", plugin{})
 	register("jekyll-sass-converter", plugin{})
 }
 
 // Some small plugins are below. More involved plugins are in separate files.
 
-// jemojiPlugin emulates the jekyll-jemoji plugin.
-type jemojiPlugin struct{ plugin }
-
-func (p jemojiPlugin) PostRender(b []byte) ([]byte, error) {
-	return utils.ApplyToHTMLText(b, func(s string) string {
-		return emoji.Sprint(s)
-	}), nil
-}
-
-// jekyllMentionsPlugin emulates the jekyll-mentions plugin.
-type jekyllMentionsPlugin struct{ plugin }
-
-var mentionPattern = regexp.MustCompile(`@(\w+)`)
-
-func (p jekyllMentionsPlugin) PostRender(b []byte) ([]byte, error) {
-	return utils.ApplyToHTMLText(b, func(s string) string {
-		return mentionPattern.ReplaceAllString(s, `<a href="https://github.com/$1" class="user-mention">@$1</a>`)
-	}), nil
-}
-
 // jekyllOptionalFrontMatterPlugin emulates the jekyll-optional-front-matter plugin.
 type jekyllOptionalFrontMatterPlugin struct{ plugin }
 
@@ -156,6 +159,38 @@ func (p jekyllOptionalFrontMatterPlugin) AfterInitSite(s Site) error {
 
 // helpers
 
+// stringKeyMap returns a copy of a map or YAML mapping with string keys, or
+// nil if the value isn't a mapping.
+func stringKeyMap(value interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	switch value := liquid.FromDrop(value).(type) {
+	case yaml.MapSlice:
+		for _, item := range value {
+			m[fmt.Sprint(item.Key)] = item.Value
+		}
+	case map[interface{}]interface{}:
+		for k, v := range value {
+			m[fmt.Sprint(k)] = v
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			m[k] = v
+		}
+	case tags.IterationKeyedMap:
+		for k, v := range value {
+			m[k] = v
+		}
+	default:
+		return nil
+	}
+	return m
+}
+
+// mapField returns a key's value in a map or YAML mapping, or nil.
+func mapField(m interface{}, key string) interface{} {
+	return stringKeyMap(m)[key]
+}
+
 // func (p plugin) stubbed(name string) {
 // 	fmt.Printf("warning: gojekyll does not emulate the %s plugin. Some tags have been stubbed to prevent errors.\n", name)
 // }
@@ -22,7 +22,7 @@ func (p *jekyllRelativeLinksPlugin) AfterInitSite(s Site) error {
 	return nil
 }
 
-func (p *jekyllRelativeLinksPlugin) PostRender(b []byte) ([]byte, error) {
+func (p *jekyllRelativeLinksPlugin) PostRender(_ Page, b []byte) ([]byte, error) {
 	return utils.ProcessAnchorHrefs(b, func(href string) string {
 		return p.processHref(href)
 	}), nil
@@ -14,6 +14,7 @@ type relativeLinksTestSite struct {
 	pages map[string]string
 }
 
+func (s relativeLinksTestSite) AddGeneratedFile(string, []byte)               {}
 func (s relativeLinksTestSite) AddHTMLPage(string, string, pages.FrontMatter) {}
 func (s relativeLinksTestSite) Config() *config.Config                        { return &s.c }
 func (s relativeLinksTestSite) HasLayout(string) bool                         { return true }
@@ -117,7 +118,7 @@ func TestRelativeLinksPlugin(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := plugin.PostRender(tt.input)
+			result, err := plugin.PostRender(nil, tt.input)
 			require.NoError(t, err)
 			require.Equal(t, string(tt.expected), string(result))
 		})
@@ -3,14 +3,20 @@ package plugins
 import (
 	"bytes"
 	"fmt"
+	"html"
+	neturl "net/url"
+	"regexp"
+	"strings"
 	"text/template"
+	"time"
 
+	"github.com/osteele/gojekyll/pages"
 	"github.com/osteele/gojekyll/utils"
 	"github.com/osteele/liquid"
 	"github.com/osteele/liquid/render"
 	"github.com/osteele/liquid/tags"
 	"github.com/tdewolff/minify"
-	"github.com/tdewolff/minify/html"
+	minifyhtml "github.com/tdewolff/minify/html"
 )
 
 type jekyllSEOTagPlugin struct {
@@ -49,62 +55,37 @@ type seoTag struct {
 	ctx render.Context
 }
 
-var seoSiteFields = []string{"url", "twitter", "facebook", "logo", "social", "google_site_verification", "lang"}
-var seoPageOrSiteFields = []string{"author", "description", "image", "author", "lang"}
+// seoTitleSeparator separates the page and site titles.
+const seoTitleSeparator = " | "
+
+// seoHomepageOrAboutMatcher matches the URLs of the pages that describe the
+// site as a whole.
+var seoHomepageOrAboutMatcher = regexp.MustCompile(`^/(about/)?(index\.html)?$`)
 
 func (p seoTag) TagBody() (string, error) {
 	var (
-		ctx          = p.ctx
-		site         = liquid.FromDrop(ctx.Get("site")).(tags.IterationKeyedMap)
-		page         = liquid.FromDrop(ctx.Get("page")).(tags.IterationKeyedMap)
-		pageTitle    = page["title"]
-		siteTitle    = site["title"]
-		canonicalURL string
+		ctx  = p.ctx
+		site = liquid.FromDrop(ctx.Get("site")).(tags.IterationKeyedMap)
+		page = liquid.FromDrop(ctx.Get("page")).(tags.IterationKeyedMap)
 	)
-
-	// Check if page has a custom canonical_url in front matter
-	canonicalURL = fmt.Sprintf("%s%s", site["url"], page["url"])
-	if pageCanonical := page["canonical_url"]; pageCanonical != nil {
-		if s, ok := pageCanonical.(string); ok && s != "" {
-			canonicalURL = s
-		}
-	}
-	if siteTitle == nil {
-		siteTitle = site["name"]
-	}
-	seoTag := map[string]interface{}{
-		"title?": true,
-		"title":  siteTitle,
-		// the following are not doc'ed, but evident from inspection:
-		// FIXME canonical w|w/out site.url and site.prefix
-		"canonical_url": canonicalURL,
-		"page_lang":     "en_US",
-		"page_title":    pageTitle,
-		"site_title":    siteTitle,
-	}
-	copyFields(seoTag, site, append(seoSiteFields, seoPageOrSiteFields...))
-	copyFields(seoTag, page, seoPageOrSiteFields)
-	// seoTag["description"] = page["excerpt"] FIXME appears to behave something like following, but excerpt should not be processed
-	if pageTitle != nil && siteTitle != nil && pageTitle != siteTitle {
-		seoTag["title"] = fmt.Sprintf("%s | %s", pageTitle, siteTitle)
-	}
-	if author, ok := seoTag["author"].(string); ok {
-		if data, _ := utils.FollowDots(site, []string{"data", "authors", author}); data != nil {
-			seoTag["author"] = data
-		}
+	seoTag := seoVariables(site, page, ctx.Get("paginator"))
+	// {% seo title=false %} omits the <title> element
+	if titleArgMatcher.MatchString(ctx.TagArgs()) {
+		seoTag["title?"] = false
 	}
-	seoTag["json_ld"] = makeJSONLD(seoTag)
 	bindings := map[string]interface{}{
-		"page":    page,
-		"site":    site,
-		"seo_tag": seoTag,
+		"jekyll":    pages.JekyllVariable(),
+		"page":      page,
+		"paginator": ctx.Get("paginator"),
+		"site":      site,
+		"seo_tag":   seoTag,
 	}
 	b, err := p.tpl.Render(bindings)
 	if err != nil {
 		return "", err
 	}
 	m := minify.New()
-	m.AddFunc("text/html", html.Minify)
+	m.AddFunc("text/html", minifyhtml.Minify)
 	min := bytes.NewBuffer(make([]byte, 0, len(b)))
 	if err := m.Minify("text/html", min, bytes.NewBuffer(b)); err != nil {
 		return "", err
@@ -112,47 +93,351 @@ func (p seoTag) TagBody() (string, error) {
 	return min.String(), nil
 }
 
-func copyFields(to, from map[string]interface{}, fields []string) {
-	for _, name := range fields {
-		if value := from[name]; value != nil {
-			to[name] = value
+var titleArgMatcher = regexp.MustCompile(`(?i)title\s*=\s*false`)
+
+// seoVariables returns the seo_tag template variable. It computes the
+// values that jekyll-seo-tag's Drop does.
+func seoVariables(site, page map[string]interface{}, paginator interface{}) map[string]interface{} {
+	var (
+		pageSEO         = mapField(page, "seo")
+		siteSocial      = mapField(site, "social")
+		url, _          = page["url"].(string)
+		homepageOrAbout = seoHomepageOrAboutMatcher.MatchString(url)
+		siteTitle       = seoFormat(firstNonNil(site["title"], site["name"]))
+		siteDescription = seoFormat(site["description"])
+		siteTagline     = seoFormat(site["tagline"])
+		pageTitle       = seoFormat(page["title"])
+	)
+	if pageTitle == nil {
+		pageTitle = siteTitle
+	}
+	if siteTagline == nil {
+		siteTagline = siteDescription
+	}
+
+	var title interface{}
+	switch {
+	case siteTitle != nil && pageTitle != siteTitle:
+		title = fmt.Sprint(pageTitle) + seoTitleSeparator + fmt.Sprint(siteTitle)
+	case siteDescription != nil && siteTitle != nil:
+		title = fmt.Sprint(siteTitle) + seoTitleSeparator + fmt.Sprint(siteTagline)
+	default:
+		title = pageTitle
+	}
+	if n, ok := seoInt(mapField(paginator, "page")); ok && n > 1 && title != nil {
+		message, ok := site["seo_paginator_message"].(string)
+		if !ok {
+			message = "Page %<current>s of %<total>s for "
+		}
+		total, _ := seoInt(mapField(paginator, "total_pages"))
+		message = strings.NewReplacer("%<current>s", fmt.Sprint(n), "%<total>s", fmt.Sprint(total)).Replace(message)
+		title = message + fmt.Sprint(title)
+	}
+
+	name := seoFormat(mapField(pageSEO, "name"))
+	if name == nil && homepageOrAbout {
+		name = firstNonNil(seoFormat(mapField(siteSocial, "name")), siteTitle)
+	}
+
+	seoType := mapField(pageSEO, "type")
+	switch {
+	case seoType != nil:
+	case homepageOrAbout:
+		seoType = "WebSite"
+	case page["date"] != nil:
+		seoType = "BlogPosting"
+	default:
+		seoType = "WebPage"
+	}
+
+	links := mapField(pageSEO, "links")
+	if links == nil && homepageOrAbout {
+		links = mapField(siteSocial, "links")
+	}
+
+	canonicalURL, _ := page["canonical_url"].(string)
+	if canonicalURL == "" {
+		siteURL, _ := site["url"].(string)
+		baseURL, _ := site["baseurl"].(string)
+		canonicalURL = utils.URLJoin(siteURL, baseURL, url)
+		if strings.HasSuffix(canonicalURL, "/index.html") {
+			canonicalURL = strings.TrimSuffix(canonicalURL, "index.html")
+		}
+	}
+
+	// In Jekyll, only posts and collection documents have excerpts.
+	description := page["description"]
+	if description == nil && page["collection"] != nil {
+		description = page["excerpt"]
+	}
+
+	pageLang := firstNonNil(page["lang"], site["lang"], "en_US")
+	pageLocale := strings.ReplaceAll(fmt.Sprint(firstNonNil(page["locale"], site["locale"], pageLang)), "-", "_")
+
+	seoTag := map[string]interface{}{
+		"title?":         title != nil,
+		"title":          title,
+		"page_title":     pageTitle,
+		"site_title":     siteTitle,
+		"site_tagline":   siteTagline,
+		"description":    firstNonNil(seoFormat(description), siteDescription),
+		"canonical_url":  canonicalURL,
+		"page_lang":      pageLang,
+		"page_locale":    pageLocale,
+		"name":           name,
+		"links":          links,
+		"type":           seoType,
+		"logo":           seoAbsoluteURL(site, site["logo"]),
+		"date_published": seoDate(page["date"]),
+		"date_modified":  seoDate(firstNonNil(mapField(pageSEO, "date_modified"), page["last_modified_at"], page["date"])),
+		"alternates":     hreflangAlternates(site, page),
+	}
+	// don't store nil maps, which templates would treat as present
+	if author := seoAuthor(site, page); author != nil {
+		seoTag["author"] = author
+	}
+	if image := seoImage(site, page); image != nil {
+		seoTag["image"] = image
+	}
+	seoTag["json_ld"] = seoJSONLD(seoTag)
+	return seoTag
+}
+
+// seoAuthor returns the page's author: the author front matter variable,
+// or the first of its authors, or the site's author. A name that is a key
+// of site.data.authors is replaced by that entry.
+func seoAuthor(site, page map[string]interface{}) map[string]interface{} {
+	author := page["author"]
+	if author == nil {
+		if authors, ok := page["authors"].([]interface{}); ok && len(authors) > 0 {
+			author = authors[0]
+		}
+	}
+	if author == nil {
+		author = site["author"]
+	}
+	if authors, ok := author.([]interface{}); ok && len(authors) > 0 {
+		author = authors[0]
+	}
+	var m map[string]interface{}
+	switch value := author.(type) {
+	case nil:
+		return nil
+	case string:
+		m = map[string]interface{}{"name": value}
+		data, _ := utils.FollowDots(site, []string{"data", "authors", value})
+		for k, v := range stringKeyMap(data) {
+			m[k] = v
 		}
+	default:
+		m = stringKeyMap(value)
 	}
+	if m == nil {
+		return nil
+	}
+	twitter := firstNonNil(m["twitter"], m["name"])
+	if twitter != nil {
+		m["twitter"] = strings.TrimPrefix(fmt.Sprint(twitter), "@")
+	}
+	return m
 }
 
-func makeJSONLD(seoTag map[string]interface{}) interface{} {
+// seoImage returns page.image as a map with an absolute path, or nil. The
+// image can be a path, or a map with path, height, width, and alt keys.
+func seoImage(site, page map[string]interface{}) map[string]interface{} {
+	var m map[string]interface{}
+	switch value := page["image"].(type) {
+	case nil:
+		return nil
+	case string:
+		m = map[string]interface{}{"path": value}
+	default:
+		m = stringKeyMap(value)
+	}
+	path := seoAbsoluteURL(site, m["path"])
+	if path == nil {
+		return nil
+	}
+	m["path"] = path
+	return m
+}
+
+// seoAbsoluteURL returns a path as an escaped absolute URL, or nil if it is
+// nil or empty.
+func seoAbsoluteURL(site map[string]interface{}, value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return nil
+	}
+	if u, err := neturl.Parse(s); err != nil || !u.IsAbs() {
+		siteURL, _ := site["url"].(string)
+		baseURL, _ := site["baseurl"].(string)
+		s = utils.URLJoin(siteURL, baseURL, s)
+	}
+	if u, err := neturl.Parse(s); err == nil {
+		s = u.String()
+	}
+	return s
+}
+
+func seoJSONLD(seoTag map[string]interface{}) map[string]interface{} {
 	jsonLD := map[string]interface{}{
-		// TODO dateModified, datePublished, publisher, mainEntityOfPage
-		"@context":    "http://schema.org",
-		"@type":       "WebPage", // FIXME this is BlogPosting for a blog page
-		"headline":    seoTag["page_title"],
+		"@context":    "https://schema.org",
+		"@type":       seoTag["type"],
 		"description": seoTag["description"],
+		"headline":    seoTag["page_title"],
+		"name":        seoTag["name"],
+		"sameAs":      seoTag["links"],
 		"url":         seoTag["canonical_url"],
 	}
-	if author := seoTag["author"]; author != nil {
-		if m, ok := author.(map[string]interface{}); ok {
-			author = m["name"]
+	if date := seoTag["date_published"]; date != nil {
+		jsonLD["datePublished"] = date
+		jsonLD["dateModified"] = seoTag["date_modified"]
+	}
+	author, _ := seoTag["author"].(map[string]interface{})
+	if author["name"] != nil {
+		authorType := firstNonNil(author["type"], "Person")
+		if authorType == "Person" || authorType == "Organization" {
+			a := map[string]interface{}{"@type": authorType, "name": author["name"]}
+			if url := author["url"]; url != nil {
+				a["url"] = url
+			}
+			jsonLD["author"] = a
 		}
-		jsonLD["author"] = map[string]interface{}{
-			"@type": "Person",
-			"name":  author,
+	}
+	if image, ok := seoTag["image"].(map[string]interface{}); ok {
+		if len(image) == 1 {
+			jsonLD["image"] = image["path"]
+		} else {
+			obj := map[string]interface{}{"@type": "imageObject"}
+			for k, v := range image {
+				obj[k] = v
+			}
+			obj["url"] = obj["path"]
+			delete(obj, "path")
+			jsonLD["image"] = obj
+		}
+	}
+	if logo := seoTag["logo"]; logo != nil {
+		publisher := map[string]interface{}{
+			"@type": "Organization",
+			"logo":  map[string]interface{}{"@type": "ImageObject", "url": logo},
+		}
+		if author["name"] != nil {
+			publisher["name"] = author["name"]
+		}
+		jsonLD["publisher"] = publisher
+	}
+	if seoTag["type"] == "BlogPosting" || seoTag["type"] == "CreativeWork" {
+		jsonLD["mainEntityOfPage"] = map[string]interface{}{"@type": "WebPage", "@id": seoTag["canonical_url"]}
+	}
+	for k, v := range jsonLD {
+		if v == nil {
+			delete(jsonLD, k)
 		}
 	}
 	return jsonLD
 }
 
+// seoFormat formats a title or description as jekyll-seo-tag does: it
+// renders Markdown, strips HTML, normalizes whitespace, and escapes HTML.
+// It returns nil for nil and empty values.
+func seoFormat(value interface{}) interface{} {
+	var s string
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s = string(value)
+	default:
+		s = fmt.Sprint(value)
+	}
+	s = html.EscapeString(stripMarkup(s))
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func seoDate(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return t.Format("2006-01-02T15:04:05-07:00")
+	}
+	return value
+}
+
+func seoInt(value interface{}) (int, bool) {
+	switch n := value.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func firstNonNil(values ...interface{}) interface{} {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// hreflangAlternates returns the URLs of a page in each of a multilingual
+// site's languages, with x-default for the default language; or nil if the
+// site isn't multilingual, or the page is excluded from localization.
+func hreflangAlternates(site, page map[string]interface{}) []map[string]interface{} {
+	var (
+		languages      []string
+		activeLang, _  = site["active_lang"].(string)
+		defaultLang, _ = site["default_lang"].(string)
+		siteURL, _     = site["url"].(string)
+		url, _         = page["url"].(string)
+	)
+	switch v := site["languages"].(type) {
+	case []string:
+		languages = v
+	case []interface{}:
+		for _, lang := range v {
+			languages = append(languages, fmt.Sprint(lang))
+		}
+	}
+	if len(languages) < 2 || activeLang == "" {
+		return nil
+	}
+	if activeLang != defaultLang {
+		prefix := "/" + activeLang
+		if !strings.HasPrefix(url, prefix+"/") {
+			return nil
+		}
+		url = strings.TrimPrefix(url, prefix)
+	}
+	alternates := make([]map[string]interface{}, 0, len(languages)+1)
+	for _, lang := range languages {
+		href := siteURL + url
+		if lang != defaultLang {
+			href = siteURL + "/" + lang + url
+		}
+		alternates = append(alternates, map[string]interface{}{"hreflang": lang, "href": href})
+	}
+	return append(alternates, map[string]interface{}{"hreflang": "x-default", "href": siteURL + url})
+}
+
 // This is a separate template so it isn't minimized away.
 var seoTagTemplate = template.Must(template.New("SEO tag").Parse(
 	`<!-- Begin Jekyll SEO tag -->
 {{.TagBody}}
 <!-- End Jekyll SEO tag -->`))
 
-// Taken verbatim from https://github.com/jekyll/jekyll-seo-tag/
 // Adapted from github.com/jekyll/jekyll-seo-tag. Used according to the MIT License.
 const seoTagTemplateSource = `{% if seo_tag.title? %}
   <title>{{ seo_tag.title }}</title>
 {% endif %}
 
+<meta name="generator" content="Jekyll v{{ jekyll.version }}" />
+
 {% if seo_tag.page_title %}
   <meta property="og:title" content="{{ seo_tag.page_title }}" />
 {% endif %}
@@ -161,11 +446,12 @@ const seoTagTemplateSource = `{% if seo_tag.title? %}
   <meta name="author" content="{{ seo_tag.author.name }}" />
 {% endif %}
 
-<meta property="og:locale" content="{{ seo_tag.page_lang | replace:'-','_' }}" />
+<meta property="og:locale" content="{{ seo_tag.page_locale }}" />
 
 {% if seo_tag.description %}
   <meta name="description" content="{{ seo_tag.description }}" />
   <meta property="og:description" content="{{ seo_tag.description }}" />
+  <meta property="twitter:description" content="{{ seo_tag.description }}" />
 {% endif %}
 
 {% if site.url %}
@@ -173,6 +459,10 @@ const seoTagTemplateSource = `{% if seo_tag.title? %}
   <meta property="og:url" content="{{ seo_tag.canonical_url }}" />
 {% endif %}
 
+{% for alternate in seo_tag.alternates %}
+  <link rel="alternate" hreflang="{{ alternate.hreflang }}" href="{{ alternate.href }}" />
+{% endfor %}
+
 {% if seo_tag.site_title %}
   <meta property="og:site_name" content="{{ seo_tag.site_title }}" />
 {% endif %}
@@ -185,31 +475,45 @@ const seoTagTemplateSource = `{% if seo_tag.title? %}
   {% if seo_tag.image.width %}
     <meta property="og:image:width" content="{{ seo_tag.image.width }}" />
   {% endif %}
+  {% if seo_tag.image.alt %}
+    <meta property="og:image:alt" content="{{ seo_tag.image.alt }}" />
+  {% endif %}
 {% endif %}
 
 {% if page.date %}
   <meta property="og:type" content="article" />
   <meta property="article:published_time" content="{{ page.date | date_to_xmlschema }}" />
+{% else %}
+  <meta property="og:type" content="website" />
 {% endif %}
 
 {% if paginator.previous_page %}
-  <link rel="prev" href="{{ paginator.previous_page_path | absolute_url }}">
+  <link rel="prev" href="{{ paginator.previous_page_path | absolute_url }}" />
 {% endif %}
 {% if paginator.next_page %}
-  <link rel="next" href="{{ paginator.next_page_path | absolute_url }}">
+  <link rel="next" href="{{ paginator.next_page_path | absolute_url }}" />
 {% endif %}
 
-{% if site.twitter %}
-  {% if seo_tag.image %}
-    <meta name="twitter:card" content="summary_large_image" />
-  {% else %}
-    <meta name="twitter:card" content="summary" />
-  {% endif %}
+{% if seo_tag.image %}
+  <meta name="twitter:card" content="{{ page.twitter.card | default: site.twitter.card | default: "summary_large_image" }}" />
+  <meta property="twitter:image" content="{{ seo_tag.image.path }}" />
+{% else %}
+  <meta name="twitter:card" content="summary" />
+{% endif %}
+
+{% if seo_tag.image.alt %}
+  <meta name="twitter:image:alt" content="{{ seo_tag.image.alt }}" />
+{% endif %}
+
+{% if seo_tag.page_title %}
+  <meta property="twitter:title" content="{{ seo_tag.page_title }}" />
+{% endif %}
 
-  <meta name="twitter:site" content="@{{ site.twitter.username | replace:"@","" }}" />
+{% if site.twitter %}
+  <meta name="twitter:site" content="@{{ site.twitter.username | remove:'@' }}" />
 
   {% if seo_tag.author.twitter %}
-    <meta name="twitter:creator" content="@{{ seo_tag.author.twitter }}" />
+    <meta name="twitter:creator" content="@{{ seo_tag.author.twitter | remove:'@' }}" />
   {% endif %}
 {% endif %}
 
@@ -229,19 +533,27 @@ const seoTagTemplateSource = `{% if seo_tag.title? %}
 
 {% if site.webmaster_verifications %}
   {% if site.webmaster_verifications.google %}
-    <meta name="google-site-verification" content="{{ site.webmaster_verifications.google }}">
+    <meta name="google-site-verification" content="{{ site.webmaster_verifications.google }}" />
   {% endif %}
 
   {% if site.webmaster_verifications.bing %}
-    <meta name="msvalidate.01" content="{{ site.webmaster_verifications.bing }}">
+    <meta name="msvalidate.01" content="{{ site.webmaster_verifications.bing }}" />
   {% endif %}
 
   {% if site.webmaster_verifications.alexa %}
-    <meta name="alexaVerifyID" content="{{ site.webmaster_verifications.alexa }}">
+    <meta name="alexaVerifyID" content="{{ site.webmaster_verifications.alexa }}" />
   {% endif %}
 
   {% if site.webmaster_verifications.yandex %}
-    <meta name="yandex-verification" content="{{ site.webmaster_verifications.yandex }}">
+    <meta name="yandex-verification" content="{{ site.webmaster_verifications.yandex }}" />
+  {% endif %}
+
+  {% if site.webmaster_verifications.baidu %}
+    <meta name="baidu-site-verification" content="{{ site.webmaster_verifications.baidu }}" />
+  {% endif %}
+
+  {% if site.webmaster_verifications.facebook %}
+    <meta name="facebook-domain-verification" content="{{ site.webmaster_verifications.facebook }}" />
   {% endif %}
 {% elsif site.google_site_verification %}
   <meta name="google-site-verification" content="{{ site.google_site_verification }}" />
@@ -1,13 +1,17 @@
 package plugins
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/filters"
 	"github.com/osteele/liquid"
 	"github.com/osteele/liquid/tags"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 func TestSEOTag(t *testing.T) {
@@ -17,8 +21,9 @@ func TestSEOTag(t *testing.T) {
 	cfg.AbsoluteURL = "http://example.com"
 	filters.AddJekyllFilters(engine, &cfg)
 	plugins := []string{"jekyll-seo-tag"}
-	_ = Install(plugins, siteFake{config.Default(), engine})
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 	bindings := liquid.Bindings{
 		"site": tags.IterationKeyedMap{
 			"title": "page title",
@@ -41,8 +46,9 @@ func TestSEOTagCanonicalURL(t *testing.T) {
 	cfg.AbsoluteURL = "http://example.com"
 	filters.AddJekyllFilters(engine, &cfg)
 	plugins := []string{"jekyll-seo-tag"}
-	_ = Install(plugins, siteFake{config.Default(), engine})
-	require.NoError(t, directory[plugins[0]].ConfigureTemplateEngine(engine))
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
 
 	t.Run("default canonical URL", func(t *testing.T) {
 		bindings := liquid.Bindings{
@@ -78,3 +84,113 @@ func TestSEOTagCanonicalURL(t *testing.T) {
 		require.NotContains(t, s, `<link rel=canonical href=http://example.com/path/to/page>`)
 	})
 }
+
+func TestSEOTagHreflangAlternates(t *testing.T) {
+	site := map[string]interface{}{
+		"url":          "http://example.com",
+		"languages":    []interface{}{"en", "es"},
+		"default_lang": "en",
+		"active_lang":  "es",
+	}
+	alternates := hreflangAlternates(site, map[string]interface{}{"url": "/es/about/"})
+	require.Equal(t, []map[string]interface{}{
+		{"hreflang": "en", "href": "http://example.com/about/"},
+		{"hreflang": "es", "href": "http://example.com/es/about/"},
+		{"hreflang": "x-default", "href": "http://example.com/about/"},
+	}, alternates)
+
+	// excluded from localization
+	require.Nil(t, hreflangAlternates(site, map[string]interface{}{"url": "/about/"}))
+
+	delete(site, "languages")
+	require.Nil(t, hreflangAlternates(site, map[string]interface{}{"url": "/es/about/"}))
+}
+
+func TestSEOTagParity(t *testing.T) {
+	engine := liquid.NewEngine()
+	cfg := config.Default()
+	filters.AddJekyllFilters(engine, &cfg)
+	plugins := []string{"jekyll-seo-tag"}
+	installed, err := Install(plugins, siteFake{config.Default(), engine})
+	require.NoError(t, err)
+	require.NoError(t, installed[plugins[0]].ConfigureTemplateEngine(engine))
+	site := tags.IterationKeyedMap{
+		"title":   "Site",
+		"url":     "https://example.com",
+		"baseurl": "/blog",
+		"logo":    "/logo.png",
+		"twitter": yaml.MapSlice{{Key: "username", Value: "@site"}},
+		"social":  yaml.MapSlice{{Key: "name", Value: "Org"}, {Key: "links", Value: []interface{}{"https://github.com/org"}}},
+		"data": map[string]interface{}{
+			"authors": map[string]interface{}{
+				"ann": map[string]interface{}{"name": "Ann Author", "twitter": "@ann", "url": "https://ann.example.com"},
+			},
+		},
+		"webmaster_verifications": yaml.MapSlice{{Key: "baidu", Value: "b1"}},
+	}
+	render := func(page tags.IterationKeyedMap, src string) string {
+		s, err := engine.ParseAndRenderString(src, liquid.Bindings{"site": site, "page": page})
+		require.NoError(t, err)
+		return s
+	}
+
+	post := render(tags.IterationKeyedMap{
+		"title":      "A *Post*",
+		"url":        "/2020/01/02/post.html",
+		"collection": "posts",
+		"date":       time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		"author":     "ann",
+		"excerpt":    "<p>The excerpt.</p>",
+		"image":      map[interface{}]interface{}{"path": "/a b.png", "width": 640, "height": 480, "alt": "Alt"},
+	}, `{% seo title=false %}`)
+	require.NotContains(t, post, "<title>")
+	require.Contains(t, post, `<meta property=og:title content="A Post">`)
+	require.Contains(t, post, `<meta name=author content="Ann Author">`)
+	require.Contains(t, post, `<meta name=description content="The excerpt.">`)
+	require.Contains(t, post, `<link rel=canonical href=https://example.com/blog/2020/01/02/post.html>`)
+	require.Contains(t, post, `<meta property=og:image content=https://example.com/blog/a%20b.png>`)
+	require.Contains(t, post, `<meta property=og:image:alt content=Alt>`)
+	require.Contains(t, post, `<meta name=twitter:card content=summary_large_image>`)
+	require.Contains(t, post, `<meta name=twitter:creator content=@ann>`)
+	require.Contains(t, post, `<meta name=baidu-site-verification content=b1>`)
+
+	jsonLD := func(s string) map[string]interface{} {
+		start := strings.Index(s, "ld+json>") + len("ld+json>")
+		end := strings.Index(s, "</script>")
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(s[start:end]), &m))
+		return m
+	}
+	ld := jsonLD(post)
+	require.Equal(t, "BlogPosting", ld["@type"])
+	require.Equal(t, "2020-01-02T00:00:00+00:00", ld["datePublished"])
+	require.Equal(t, map[string]interface{}{"@type": "Person", "name": "Ann Author", "url": "https://ann.example.com"}, ld["author"])
+	require.Equal(t, map[string]interface{}{"@type": "WebPage", "@id": "https://example.com/blog/2020/01/02/post.html"}, ld["mainEntityOfPage"])
+	require.Equal(t, "https://example.com/blog/logo.png", ld["publisher"].(map[string]interface{})["logo"].(map[string]interface{})["url"])
+	require.Equal(t, "imageObject", ld["image"].(map[string]interface{})["@type"])
+
+	home := render(tags.IterationKeyedMap{"url": "/index.html"}, `{% seo %}`)
+	require.Contains(t, home, "<title>Site</title>")
+	require.Contains(t, home, `<link rel=canonical href=https://example.com/blog/>`)
+	require.Contains(t, home, `<meta name=twitter:card content=summary>`)
+	ld = jsonLD(home)
+	require.Equal(t, "WebSite", ld["@type"])
+	require.Equal(t, "Org", ld["name"])
+	require.Equal(t, []interface{}{"https://github.com/org"}, ld["sameAs"])
+
+	page := render(tags.IterationKeyedMap{
+		"title":         "Page",
+		"url":           "/page.html",
+		"canonical_url": "https://elsewhere.example.com/page",
+		"image":         "https://cdn.example.com/i.png",
+		"twitter":       map[interface{}]interface{}{"card": "summary"},
+		"seo":           map[interface{}]interface{}{"type": "Organization", "name": "Acme"},
+	}, `{% seo %}`)
+	require.Contains(t, page, "<title>Page | Site</title>")
+	require.Contains(t, page, `<link rel=canonical href=https://elsewhere.example.com/page>`)
+	require.Contains(t, page, `<meta name=twitter:card content=summary>`)
+	ld = jsonLD(page)
+	require.Equal(t, "Organization", ld["@type"])
+	require.Equal(t, "Acme", ld["name"])
+	require.Equal(t, "https://cdn.example.com/i.png", ld["image"])
+}
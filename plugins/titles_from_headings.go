@@ -0,0 +1,94 @@
+package plugins
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	blackfriday "github.com/danog/blackfriday/v2"
+	"github.com/osteele/gojekyll/templates"
+	"github.com/osteele/gojekyll/utils"
+)
+
+type jekyllTitlesFromHeadingsPlugin struct{ plugin }
+
+func init() {
+	register("jekyll-titles-from-headings", jekyllTitlesFromHeadingsPlugin{})
+}
+
+// A rawContentPage is a page whose source can be modified before it is
+// rendered.
+type rawContentPage interface {
+	RawContent() []byte
+	SetRawContent([]byte)
+}
+
+// titleHeadingMatcher matches a level 1-3 ATX heading, or a setext heading,
+// at the start of a document. This is the plugin's TITLE_REGEX.
+var titleHeadingMatcher = regexp.MustCompile(`(?m)\A\s*(?:#{1,3}\s+(.*?)(?:\s+#+)?|(.*)\r?\n[-=]+\s*)$`)
+
+// PostInitPage sets the title of a Markdown page that doesn't have one to
+// the text of its first heading. With the titles_from_headings.strip_title
+// option, the heading is removed from the page.
+func (p jekyllTitlesFromHeadingsPlugin) PostInitPage(s Site, pg Page) error {
+	var (
+		cfg, _          = s.Config().Map("titles_from_headings")
+		options         = templates.VariableMap(cfg)
+		fm              = pg.FrontMatter()
+		_, inCollection = fm["collection"]
+	)
+	switch {
+	case !options.Bool("enabled", true):
+		return nil
+	case inCollection && !options.Bool("collections", false):
+		return nil
+	case !s.Config().IsMarkdown(pg.Source()) || hasTitle(pg):
+		return nil
+	}
+	rp, ok := pg.(rawContentPage)
+	if !ok {
+		return nil
+	}
+	raw := rp.RawContent()
+	m := titleHeadingMatcher.FindSubmatchIndex(raw)
+	if m == nil {
+		return nil
+	}
+	var heading []byte
+	if m[2] >= 0 {
+		heading = raw[m[2]:m[3]]
+	} else {
+		heading = raw[m[4]:m[5]]
+	}
+	fm["title"] = stripMarkup(string(heading))
+	if options.Bool("strip_title", false) {
+		// replace the heading by blank lines, so that line numbers in
+		// error messages don't change
+		blank := strings.Repeat("\n", strings.Count(string(raw[m[0]:m[1]]), "\n"))
+		rp.SetRawContent(append([]byte(blank), raw[m[1]:]...))
+	}
+	return nil
+}
+
+// hasTitle returns true if a page's front matter declares a title. A post's
+// title that is inferred from its filename doesn't count.
+func hasTitle(pg Page) bool {
+	title, ok := pg.FrontMatter()["title"]
+	if !ok || title == nil {
+		return false
+	}
+	if _, inferred, found := utils.ParseFilenameDateTitle(pg.Source()); found && pg.IsPost() && title == inferred {
+		return false
+	}
+	return true
+}
+
+var htmlTagMatcher = regexp.MustCompile(`<[^>]*>`)
+
+// stripMarkup returns the text of a heading's Markdown, as the plugin's
+// markdownify | strip_html | normalize_whitespace does.
+func stripMarkup(s string) string {
+	s = string(blackfriday.Run([]byte(s)))
+	s = html.UnescapeString(htmlTagMatcher.ReplaceAllString(s, ""))
+	return strings.Join(strings.Fields(s), " ")
+}
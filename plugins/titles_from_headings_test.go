@@ -0,0 +1,53 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/stretchr/testify/require"
+)
+
+// rawMockPage is a mockPage with a source file and raw content.
+type rawMockPage struct {
+	mockPage
+	source string
+	raw    string
+}
+
+func (p *rawMockPage) Source() string         { return p.source }
+func (p *rawMockPage) RawContent() []byte     { return []byte(p.raw) }
+func (p *rawMockPage) SetRawContent(b []byte) { p.raw = string(b) }
+
+func TestTitlesFromHeadings(t *testing.T) {
+	plugin := jekyllTitlesFromHeadingsPlugin{}
+	site := &mockSite{cfg: &config.Config{MarkdownExt: "md"}}
+	title := func(source, raw string, fm pages.FrontMatter) interface{} {
+		pg := &rawMockPage{mockPage{fm: fm}, source, raw}
+		require.NoError(t, plugin.PostInitPage(site, pg))
+		return pg.FrontMatter()["title"]
+	}
+
+	require.Equal(t, "Title", title("a.md", "# Title\n\nText", nil))
+	require.Equal(t, "Title", title("a.md", "\n## Title ##\n", nil))
+	require.Equal(t, "Setext Title", title("a.md", "Setext Title\n============\n\nText", nil))
+	require.Equal(t, "A linked title", title("a.md", "# A [linked](/) *title*", nil))
+	require.Nil(t, title("a.md", "Text\n\n# Title", nil))
+	require.Nil(t, title("a.md", "#### Title", nil))
+	require.Nil(t, title("a.html", "# Title", nil))
+	require.Equal(t, "Declared", title("a.md", "# Title", pages.FrontMatter{"title": "Declared"}))
+
+	// collection documents need the collections option
+	require.Nil(t, title("_docs/a.md", "# Title", pages.FrontMatter{"collection": "docs"}))
+
+	*site.cfg = config.FromString("markdown_ext: md\ntitles_from_headings:\n  collections: true\n  strip_title: true")
+	// a post's title from its filename is replaced
+	pg := &rawMockPage{mockPage{fm: pages.FrontMatter{"collection": "posts", "title": "My Post"}, isPost: true},
+		"_posts/2020-01-02-my-post.md", "# Heading\n\nText"}
+	require.NoError(t, plugin.PostInitPage(site, pg))
+	require.Equal(t, "Heading", pg.FrontMatter()["title"])
+	require.Equal(t, "\n\nText", pg.raw)
+
+	*site.cfg = config.FromString("markdown_ext: md\ntitles_from_headings:\n  enabled: false")
+	require.Nil(t, title("a.md", "# Title", nil))
+}
@@ -15,8 +15,7 @@ import (
 // ApplyLayout applies the named layout to the content.
 func (p *Manager) ApplyLayout(name string, content []byte, vars liquid.Bindings) ([]byte, error) {
 	for name != "" {
-		var lfm map[string]interface{}
-		tpl, err := p.FindLayout(name, &lfm)
+		filename, src, lineNo, lfm, err := p.readLayout(name)
 		if err != nil {
 			return nil, err
 		}
@@ -24,7 +23,7 @@ func (p *Manager) ApplyLayout(name string, content []byte, vars liquid.Bindings)
 			"content": string(content),
 			"layout":  lfm,
 		})
-		content, err = tpl.Render(b)
+		content, err = p.renderLiquid(src, b, filename, lineNo)
 		if err != nil {
 			return nil, utils.WrapPathError(err, name)
 		}
@@ -35,16 +34,33 @@ func (p *Manager) ApplyLayout(name string, content []byte, vars liquid.Bindings)
 
 // FindLayout returns a template for the named layout.
 func (p *Manager) FindLayout(base string, fmp *map[string]interface{}) (tpl *liquid.Template, err error) {
+	filename, content, lineNo, fm, err := p.readLayout(base)
+	if err != nil {
+		return nil, err
+	}
+	if fmp != nil {
+		*fmp = fm
+	}
+	return p.liquidEngine.ParseTemplateLocation(content, filename, lineNo)
+}
+
+// LayoutExists returns true if there is a layout with the name. Unlike
+// FindLayout, it doesn't parse the layout.
+func (p *Manager) LayoutExists(name string) bool {
+	_, _, _, _, err := p.readLayout(name)
+	return err == nil
+}
+
+// readLayout reads the named layout. It returns the layout's filename, its
+// content after the front matter, the content's line number, and the front
+// matter.
+func (p *Manager) readLayout(base string) (filename string, content []byte, lineNo int, fm map[string]interface{}, err error) {
 	// not cached, but the time here is negligible
 	exts := []string{"", ".html"}
 	for _, ext := range strings.Split(p.cfg.MarkdownExt, `,`) {
 		exts = append(exts, "."+ext)
 	}
-	var (
-		filename string
-		content  []byte
-		found    bool
-	)
+	found := false
 loop:
 	for _, dir := range p.layoutDirs() {
 		for _, ext := range exts {
@@ -55,25 +71,16 @@ loop:
 				break loop
 			}
 			if !os.IsNotExist(err) {
-				return nil, err
+				return
 			}
 		}
 	}
 	if !found {
-		return nil, fmt.Errorf("no template for %s", base)
-	}
-	lineNo := 1
-	fm, err := frontmatter.Read(&content, &lineNo)
-	if err != nil {
+		err = fmt.Errorf("no template for %s", base)
 		return
 	}
-	if fmp != nil {
-		*fmp = fm
-	}
-	tpl, err = p.liquidEngine.ParseTemplateLocation(content, filename, lineNo)
-	if err != nil {
-		return nil, err
-	}
+	lineNo = 1
+	fm, err = frontmatter.Read(&content, &lineNo, false)
 	return
 }
 
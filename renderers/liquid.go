@@ -0,0 +1,170 @@
+package renderers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/osteele/gojekyll/filters"
+	"github.com/osteele/gojekyll/logger"
+	"github.com/osteele/gojekyll/tags"
+	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/expressions"
+)
+
+// makeLiquidEngines creates the Liquid engine, configured from the liquid
+// section of the site configuration.
+//
+// In warn mode (liquid.error_mode: warn), the engine is lenient, and a
+// second engine, with the same tags and filters, checks for undefined
+// variables and filters; see renderLiquid. Otherwise the second engine is
+// nil.
+func (p *Manager) makeLiquidEngines() (engine, check *liquid.Engine) {
+	engine = p.newLiquidEngine()
+	p.probeEngine = p.newLiquidEngine()
+
+	lc := p.cfg.Liquid
+	strictFilters := lc.StrictFilters == nil || *lc.StrictFilters
+	if !isLiquidWarnMode(lc.ErrorMode) {
+		if lc.StrictVariables {
+			engine.StrictVariables()
+		}
+		if !strictFilters {
+			engine.LaxFilters()
+		}
+		return engine, nil
+	}
+	if !lc.StrictVariables && !strictFilters {
+		engine.LaxFilters()
+		return engine, nil
+	}
+	check = p.newLiquidEngine()
+	if lc.StrictVariables {
+		check.StrictVariables()
+	}
+	if !strictFilters {
+		check.LaxFilters()
+	}
+	engine.LaxFilters()
+	return engine, check
+}
+
+// newLiquidEngine creates a Liquid engine with the Jekyll filters and tags.
+func (p *Manager) newLiquidEngine() *liquid.Engine {
+	dirs := []string{filepath.Join(p.cfg.Source, p.cfg.IncludesDir)}
+	if p.ThemeDir != "" {
+		dirs = append(dirs, filepath.Join(p.ThemeDir, "_includes"))
+	}
+	engine := liquid.NewEngine()
+	filters.AddJekyllFilters(engine, &p.cfg)
+	cache := tags.AddJekyllTags(engine, &p.cfg, dirs, p.RelativeFilenameToURL)
+	p.includeCaches = append(p.includeCaches, cache)
+	return engine
+}
+
+// ClearCaches clears the output of include_cached tags, so that pages that
+// are rendered again see changes to the included files and site variables.
+func (p *Manager) ClearCaches() {
+	for _, c := range p.includeCaches {
+		c.Clear()
+	}
+}
+
+// ConfigureTemplateEngines calls fn with each of the Liquid engines, so that
+// plugins can add the same tags and filters to all of them. The engine that
+// TemplateEngine returns is last.
+func (p *Manager) ConfigureTemplateEngines(fn func(*liquid.Engine) error) error {
+	engines := []*liquid.Engine{p.probeEngine}
+	if p.checkEngine != nil {
+		engines = append(engines, p.checkEngine)
+	}
+	for _, e := range append(engines, p.liquidEngine) {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTagDefined returns true if the Liquid engine defines the named tag or
+// block, including those that plugins add.
+func (p *Manager) IsTagDefined(name string) bool {
+	_, err := p.probeEngine.ParseString("{% " + name + " %}")
+	return err == nil || !strings.Contains(err.Error(), "undefined tag")
+}
+
+// IsFilterDefined returns true if the Liquid engine defines the named
+// filter, including those that plugins add. Unlike rendering, this doesn't
+// depend on the liquid.strict_filters setting.
+func (p *Manager) IsFilterDefined(name string) bool {
+	_, err := p.probeEngine.ParseAndRenderString("{{ nil | "+name+" }}", liquid.Bindings{})
+	return err == nil || !strings.Contains(err.Error(), "undefined filter")
+}
+
+// TemplateDirs returns the directories that contain layouts and includes,
+// including the theme's.
+func (p *Manager) TemplateDirs() []string {
+	dirs := append(p.layoutDirs(), filepath.Join(p.sourceDir(), p.cfg.IncludesDir))
+	if p.ThemeDir != "" {
+		dirs = append(dirs, filepath.Join(p.ThemeDir, "_includes"))
+	}
+	return dirs
+}
+
+func isLiquidWarnMode(mode string) bool {
+	return mode == "warn" || mode == "warning"
+}
+
+// renderLiquid parses and renders a template. In warn mode, an undefined
+// variable or filter is logged, with the template filename and line number.
+//
+// The Liquid engine stops at the first undefined variable or filter, so a
+// template that has one is rendered again without the check. Afterwards,
+// that template is rendered once, without the check; it is only reported
+// the first time.
+func (p *Manager) renderLiquid(src []byte, vars liquid.Bindings, filename string, lineNo int) ([]byte, error) {
+	engine := p.liquidEngine
+	key := fmt.Sprintf("%s:%d:%s", filename, lineNo, src)
+	if _, reported := p.undefinedReported.Load(key); p.checkEngine != nil && !reported {
+		engine = p.checkEngine
+	}
+	tpl, err := engine.ParseTemplateLocation(src, filename, lineNo)
+	if err != nil {
+		return nil, err
+	}
+	out, err := tpl.Render(vars)
+	if err != nil && engine == p.checkEngine && isUndefinedError(err) {
+		p.undefinedReported.Store(key, true)
+		logger.Default().Warn("%s", err)
+		return p.renderLiquid(src, vars, filename, lineNo)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// undefinedVariableMessage is the message of the error that the Liquid
+// engine reports for an undefined variable in strict mode. Unlike an
+// undefined filter, this error doesn't have a type of its own.
+const undefinedVariableMessage = "undefined variable"
+
+// isUndefinedError returns true if a rendering error is due to an undefined
+// variable or filter.
+func isUndefinedError(err error) bool {
+	for err != nil {
+		if _, ok := err.(expressions.UndefinedFilter); ok {
+			return true
+		}
+		if c, ok := err.(interface{ Cause() error }); ok && c.Cause() != nil {
+			err = c.Cause()
+			continue
+		}
+		if err.Error() == undefinedVariableMessage {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package renderers
+
+import (
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/osteele/liquid"
+	"github.com/osteele/liquid/render"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_RenderTemplate_strictness(t *testing.T) {
+	render := func(cfg string, src string) (string, error) {
+		c := config.FromString(cfg)
+		c.Source = t.TempDir()
+		p, err := New(c, Options{})
+		require.NoError(t, err)
+		b, err := p.RenderTemplate([]byte(src), liquid.Bindings{"page": map[string]interface{}{"title": "T"}}, "page.html", 1)
+		return string(b), err
+	}
+
+	// by default, undefined variables are empty, and undefined filters are errors
+	s, err := render("", "{{ page.titel }}|{{ page.title }}")
+	require.NoError(t, err)
+	require.Equal(t, "|T", s)
+	_, err = render("", `{{ "x" | nofilter }}`)
+	require.Error(t, err)
+
+	_, err = render("liquid:\n  strict_variables: true\n", "\n{{ page.titel }}")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 2")
+	require.Contains(t, err.Error(), "page.html")
+
+	s, err = render("liquid:\n  strict_filters: false\n", `{{ "x" | nofilter }}`)
+	require.NoError(t, err)
+	require.Equal(t, "x", s)
+
+	// in warn mode, the problem is logged and the template is rendered leniently
+	s, err = render("liquid:\n  error_mode: warn\n  strict_variables: true\n  strict_filters: true\n", `{{ page.titel }}{{ page.title | nofilter }}`)
+	require.NoError(t, err)
+	require.Equal(t, "T", s)
+}
+
+func TestManager_ConfigureTemplateEngines(t *testing.T) {
+	c := config.FromString("liquid:\n  error_mode: warn\n  strict_variables: true\n")
+	c.Source = t.TempDir()
+	p, err := New(c, Options{})
+	require.NoError(t, err)
+	count := 0
+	require.NoError(t, p.ConfigureTemplateEngines(func(e *liquid.Engine) error {
+		e.RegisterTag("count", func(render.Context) (string, error) {
+			count++
+			return "", nil
+		})
+		return nil
+	}))
+	require.True(t, p.IsTagDefined("count"))
+
+	bindings := liquid.Bindings{"page": map[string]interface{}{"title": "T"}}
+	b, err := p.RenderTemplate([]byte("{% count %}{{ page.title }}"), bindings, "page.html", 1)
+	require.NoError(t, err)
+	require.Equal(t, "T", string(b))
+	require.Equal(t, 1, count)
+
+	// once a template has reported an undefined variable, it is rendered once
+	src := []byte("{{ page.titel }}{% count %}{{ page.title }}")
+	for i := 0; i < 2; i++ {
+		count = 0
+		b, err = p.RenderTemplate(src, bindings, "layout.html", 1)
+		require.NoError(t, err)
+		require.Equal(t, "T", string(b))
+		require.Equal(t, 1, count)
+	}
+}
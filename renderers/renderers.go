@@ -2,13 +2,11 @@ package renderers
 
 import (
 	"io"
-	"path/filepath"
 	"strings"
 	"sync"
 
 	sass "github.com/bep/godartsass/v2"
 	"github.com/osteele/gojekyll/config"
-	"github.com/osteele/gojekyll/filters"
 	"github.com/osteele/gojekyll/internal/sasserrors"
 	"github.com/osteele/gojekyll/tags"
 	"github.com/osteele/gojekyll/utils"
@@ -35,10 +33,14 @@ type Renderers interface {
 // Manager applies a rendering transformation to a file.
 type Manager struct {
 	Options
-	cfg          config.Config
-	liquidEngine *liquid.Engine
-	sassTempDir  string
-	sassHash     string
+	cfg               config.Config
+	liquidEngine      *liquid.Engine
+	checkEngine       *liquid.Engine // in liquid warn mode, reports undefined variables and filters
+	probeEngine       *liquid.Engine // has the same tags and filters, with strict filters; see IsFilterDefined
+	includeCaches     []*tags.IncludeCache
+	sassTempDir       string
+	sassHash          string
+	undefinedReported sync.Map // templates whose undefined variables or filters were reported; see renderLiquid
 }
 
 // Options configures a rendering manager.
@@ -50,7 +52,7 @@ type Options struct {
 // New makes a rendering manager.
 func New(c config.Config, options Options) (*Manager, error) {
 	p := Manager{Options: options, cfg: c}
-	p.liquidEngine = p.makeLiquidEngine()
+	p.liquidEngine, p.checkEngine = p.makeLiquidEngines()
 	if err := p.copySASSFileIncludes(); err != nil {
 		return nil, err
 	}
@@ -162,28 +164,13 @@ func parseInt(s string, defaultVal int) int {
 
 // RenderTemplate renders a Liquid template
 func (p *Manager) RenderTemplate(src []byte, vars liquid.Bindings, filename string, lineNo int) ([]byte, error) {
-	tpl, err := p.liquidEngine.ParseTemplateLocation(src, filename, lineNo)
-	if err != nil {
-		return nil, utils.WrapPathError(err, filename)
-	}
-	out, err := tpl.Render(vars)
+	out, err := p.renderLiquid(src, vars, filename, lineNo)
 	if err != nil {
 		return nil, utils.WrapPathError(err, filename)
 	}
 	return out, err
 }
 
-func (p *Manager) makeLiquidEngine() *liquid.Engine {
-	dirs := []string{filepath.Join(p.cfg.Source, p.cfg.IncludesDir)}
-	if p.ThemeDir != "" {
-		dirs = append(dirs, filepath.Join(p.ThemeDir, "_includes"))
-	}
-	engine := liquid.NewEngine()
-	filters.AddJekyllFilters(engine, &p.cfg)
-	tags.AddJekyllTags(engine, &p.cfg, dirs, p.RelativeFilenameToURL)
-	return engine
-}
-
 // getSassTranspiler returns the global SASS transpiler singleton, initializing it if necessary.
 // Using a global singleton avoids race conditions when Sites are reloaded during watch mode,
 // and matches the godartsass recommendation to "create one and use that for all SCSS processing."
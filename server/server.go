@@ -60,12 +60,14 @@ func (s *Server) handler(rw http.ResponseWriter, r *http.Request) {
 		site     = s.Site
 		urlpath  = r.URL.Path
 		p, found = site.URLPage(urlpath)
+		status   = http.StatusOK
 	)
 	if !found {
-		rw.WriteHeader(http.StatusNotFound)
+		status = http.StatusNotFound
 		p, found = site.Routes["/404.html"]
 	}
 	if !found {
+		rw.WriteHeader(status)
 		_, err := fmt.Fprintf(rw, "404 page not found: %s\n", urlpath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
@@ -76,7 +78,10 @@ func (s *Server) handler(rw http.ResponseWriter, r *http.Request) {
 	if mimeType != "" {
 		rw.Header().Set("Content-Type", mimeType)
 	}
-	var w io.Writer = rw
+	// Render into a buffer, so that the response can be compressed the same
+	// way as the precompressed output files.
+	buf := new(bytes.Buffer)
+	var w io.Writer = buf
 	if strings.HasPrefix(mimeType, "text/html;") && site.Config().Watch {
 		w = NewLiveReloadInjector(w)
 	}
@@ -98,6 +103,21 @@ func (s *Server) handler(rw http.ResponseWriter, r *http.Request) {
 			fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
 		}
 	}
+	b, encoding, err := site.ContentEncoding(p, buf.Bytes(), r.Header.Get("Accept-Encoding"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error compressing %s: %s\n", urlpath, err)
+		b, encoding = buf.Bytes(), ""
+	}
+	if len(site.Config().Precompress.Formats) > 0 {
+		rw.Header().Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		rw.Header().Set("Content-Encoding", encoding)
+	}
+	rw.WriteHeader(status)
+	if _, err := rw.Write(b); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing HTTP response: %s", err)
+	}
 }
 
 func fileErrorContext(e error) (s, path string) {
@@ -0,0 +1,243 @@
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Audit issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// An AuditIssue is an HTML validation or accessibility problem in a
+// rendered page.
+type AuditIssue struct {
+	Source   string `json:"source"` // as for BrokenLink.Source
+	Output   string `json:"output"` // as for BrokenLink.Output
+	URL      string `json:"url"`
+	Line     int    `json:"line"` // line number in the output file, not the source file
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (i AuditIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s] (in %s)", i.Output, i.Line, i.Severity, i.Message, i.Rule, i.Source)
+}
+
+// Audit renders the site's HTML pages, and returns the markup and
+// accessibility problems in them: images without alt text, duplicate ids,
+// missing titles and lang attributes, skipped heading levels, links without
+// text, and mismatched tags.
+func (s *Site) Audit() ([]AuditIssue, error) {
+	urls, rendered, err := s.renderHTMLDocs()
+	if err != nil {
+		return nil, err
+	}
+	var issues []AuditIssue
+	for _, u := range urls {
+		d := s.Routes[u]
+		source, output := s.docSourceName(u, d), s.docOutputName(d)
+		for _, issue := range auditHTML(rendered[d]) {
+			issue.Source, issue.Output, issue.URL = source, output, u
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// voidElements don't have end tags.
+var voidElements = map[atom.Atom]bool{
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true,
+	atom.Embed: true, atom.Hr: true, atom.Img: true, atom.Input: true,
+	atom.Link: true, atom.Meta: true, atom.Param: true, atom.Source: true,
+	atom.Track: true, atom.Wbr: true,
+}
+
+// optionalEndTags are elements whose end tags HTML lets authors omit.
+var optionalEndTags = map[atom.Atom]bool{
+	atom.Body: true, atom.Colgroup: true, atom.Dd: true, atom.Dt: true,
+	atom.Head: true, atom.Html: true, atom.Li: true, atom.Optgroup: true,
+	atom.Option: true, atom.P: true, atom.Rp: true, atom.Rt: true,
+	atom.Tbody: true, atom.Td: true, atom.Tfoot: true, atom.Th: true,
+	atom.Thead: true, atom.Tr: true,
+}
+
+type openElement struct {
+	tag  atom.Atom
+	name string
+	line int
+}
+
+// An htmlAuditor accumulates the state of a single document's audit.
+type htmlAuditor struct {
+	issues  []AuditIssue
+	ids     map[string]int // id -> line of first use
+	stack   []openElement
+	heading int // level of the previous heading, or 0
+
+	// the link whose text is being collected, if any
+	link        *openElement
+	linkHasText bool
+
+	hasHTML, hasTitle bool
+	inTitle           bool
+	titleText         string
+}
+
+// auditHTML returns the issues in an HTML document. Source, Output, and URL
+// are left for the caller to fill in.
+func auditHTML(doc []byte) []AuditIssue {
+	a := htmlAuditor{ids: map[string]int{}}
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	line := 1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != io.EOF {
+				a.report(line, SeverityError, "malformed", "%s", err)
+			}
+			break
+		}
+		raw := z.Raw()
+		tok := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			a.startTag(tok, line, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			a.endTag(tok, line)
+		case html.TextToken:
+			a.text(tok.Data)
+		}
+		line += bytes.Count(raw, []byte("\n"))
+	}
+	a.finish()
+	sort.SliceStable(a.issues, func(i, j int) bool { return a.issues[i].Line < a.issues[j].Line })
+	return a.issues
+}
+
+func (a *htmlAuditor) report(line int, severity, rule, format string, args ...interface{}) {
+	a.issues = append(a.issues, AuditIssue{
+		Line:     line,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (a *htmlAuditor) startTag(tok html.Token, line int, selfClosing bool) {
+	attrs := map[string]string{}
+	for _, attr := range tok.Attr {
+		attrs[attr.Key] = attr.Val
+	}
+	if id, ok := attrs["id"]; ok {
+		if prev, seen := a.ids[id]; seen {
+			a.report(line, SeverityError, "duplicate-id", "duplicate id %q; first used on line %d", id, prev)
+		} else {
+			a.ids[id] = line
+		}
+	}
+	switch tok.DataAtom {
+	case atom.Html:
+		a.hasHTML = true
+		if strings.TrimSpace(attrs["lang"]) == "" {
+			a.report(line, SeverityWarning, "html-lang", "<html> element has no lang attribute")
+		}
+	case atom.Title:
+		a.hasTitle, a.inTitle = true, true
+	case atom.Img:
+		if _, ok := attrs["alt"]; !ok && attrs["role"] != "presentation" {
+			a.report(line, SeverityError, "img-alt", "<img> element has no alt attribute")
+		} else if a.link != nil && attrs["alt"] != "" {
+			a.linkHasText = true
+		}
+	case atom.A:
+		if _, ok := attrs["href"]; ok && !selfClosing {
+			a.link = &openElement{tok.DataAtom, tok.Data, line}
+			a.linkHasText = strings.TrimSpace(attrs["aria-label"]+attrs["aria-labelledby"]+attrs["title"]) != ""
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(tok.Data[1:])
+		if a.heading > 0 && level > a.heading+1 {
+			a.report(line, SeverityWarning, "heading-order", "<%s> follows <h%d>; heading levels should only increase by one", tok.Data, a.heading)
+		}
+		a.heading = level
+	}
+	if a.link != nil && tok.DataAtom == atom.Svg && attrs["aria-label"] != "" {
+		a.linkHasText = true
+	}
+	if selfClosing || voidElements[tok.DataAtom] {
+		return
+	}
+	// An element that the start tag implicitly closes, such as a <p> that
+	// starts a new <p>, isn't left open.
+	if n := len(a.stack); n > 0 && optionalEndTags[a.stack[n-1].tag] && a.stack[n-1].tag == tok.DataAtom {
+		a.stack = a.stack[:n-1]
+	}
+	a.stack = append(a.stack, openElement{tok.DataAtom, tok.Data, line})
+}
+
+func (a *htmlAuditor) endTag(tok html.Token, line int) {
+	switch tok.DataAtom {
+	case atom.Title:
+		a.inTitle = false
+	case atom.A:
+		if a.link != nil {
+			if !a.linkHasText {
+				a.report(a.link.line, SeverityError, "empty-link", "link has no text")
+			}
+			a.link = nil
+		}
+	}
+	if voidElements[tok.DataAtom] {
+		return
+	}
+	for i := len(a.stack) - 1; i >= 0; i-- {
+		e := a.stack[i]
+		if e.name == tok.Data {
+			for _, unclosed := range a.stack[i+1:] {
+				a.reportUnclosed(unclosed)
+			}
+			a.stack = a.stack[:i]
+			return
+		}
+	}
+	a.report(line, SeverityError, "malformed", "</%s> has no matching start tag", tok.Data)
+}
+
+func (a *htmlAuditor) text(s string) {
+	if a.inTitle {
+		a.titleText += s
+	}
+	if a.link != nil && strings.TrimSpace(s) != "" {
+		a.linkHasText = true
+	}
+}
+
+func (a *htmlAuditor) reportUnclosed(e openElement) {
+	if !optionalEndTags[e.tag] {
+		a.report(e.line, SeverityError, "malformed", "<%s> is not closed", e.name)
+	}
+}
+
+func (a *htmlAuditor) finish() {
+	for _, e := range a.stack {
+		a.reportUnclosed(e)
+	}
+	// Pages without an <html> element are fragments, such as the output of
+	// a page without a layout; the document-level rules don't apply.
+	if a.hasHTML {
+		if !a.hasTitle || strings.TrimSpace(a.titleText) == "" {
+			a.report(1, SeverityError, "document-title", "document has no <title>")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditHTML(t *testing.T) {
+	rules := func(doc string) (result []string) {
+		for _, issue := range auditHTML([]byte(doc)) {
+			result = append(result, issue.Rule)
+		}
+		return
+	}
+	page := func(body string) string {
+		return "<!DOCTYPE html><html lang=\"en\"><head><title>T</title></head><body>" + body + "</body></html>"
+	}
+	require.Empty(t, rules(page(`<h1 id="a">A</h1><h2 id="b">B</h2><p><a href="/"><img src="x.png" alt="Home"></a><p>para`)))
+	require.Empty(t, rules(`<p>a fragment`))
+
+	require.Equal(t, []string{"img-alt"}, rules(page(`<img src="x.png">`)))
+	require.Equal(t, []string{"duplicate-id"}, rules(page(`<div id="a"></div><span id="a"></span>`)))
+	require.Equal(t, []string{"heading-order"}, rules(page(`<h1>A</h1><h3>B</h3>`)))
+	require.Equal(t, []string{"empty-link"}, rules(page(`<a href="/"> </a><a href="/" aria-label="Home"></a>`)))
+	require.Equal(t, []string{"malformed", "malformed"}, rules(page(`<div><span></div></em>`)))
+	require.Equal(t, []string{"html-lang", "document-title"}, rules(`<html><head></head><body></body></html>`))
+
+	issues := auditHTML([]byte("<p>\n<img src=\"x.png\">"))
+	require.Len(t, issues, 1)
+	require.Equal(t, 2, issues[0].Line)
+	require.Equal(t, SeverityError, issues[0].Severity)
+	issues[0].Source, issues[0].Output = "page.md", "_site/page.html"
+	require.Equal(t, "_site/page.html:2: error: "+issues[0].Message+" [img-alt] (in page.md)", issues[0].String())
+}
@@ -34,6 +34,10 @@ func (s *Site) Clean() error {
 			return nil
 		case s.KeepFile(utils.MustRel(s.DestDir(), filename)):
 			return nil
+		case s.isPrecompressedFile(filename):
+			// writePrecompressedFiles compares these to the new output, and
+			// removes those that are stale
+			return nil
 		case s.cfg.DryRun:
 			return nil
 		default:
@@ -0,0 +1,248 @@
+package site
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/osteele/gojekyll/logger"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// defaultPrecompressMinSize is the size below which output files aren't
+// precompressed, if precompress.min_size isn't set.
+const defaultPrecompressMinSize = 1024
+
+// A compression is a content encoding that output files can be precompressed with.
+type compression struct {
+	name       string // as listed in precompress.formats
+	encoding   string // HTTP Content-Encoding
+	ext        string // appended to the name of the output file
+	newWriter  func(io.Writer) io.WriteCloser
+	newReader  func(io.Reader) (io.Reader, error)
+	fastWriter func(io.Writer) io.WriteCloser // for HTTP responses, which are compressed per request
+}
+
+// compressions is ordered by preference, for content negotiation.
+var compressions = []compression{
+	{
+		name:       "brotli",
+		encoding:   "br",
+		ext:        ".br",
+		newWriter:  func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.BestCompression) },
+		newReader:  func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		fastWriter: func(w io.Writer) io.WriteCloser { return brotli.NewWriterLevel(w, brotli.BestSpeed) },
+	},
+	{
+		name:     "gzip",
+		encoding: "gzip",
+		ext:      ".gz",
+		newWriter: func(w io.Writer) io.WriteCloser {
+			gw, _ := gzip.NewWriterLevel(w, gzip.BestCompression) // the level is valid
+			return gw
+		},
+		newReader: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		fastWriter: func(w io.Writer) io.WriteCloser {
+			gw, _ := gzip.NewWriterLevel(w, gzip.BestSpeed) // the level is valid
+			return gw
+		},
+	},
+}
+
+// compressibleExts are the extensions of text output files.
+var compressibleExts = utils.StringArrayToMap([]string{
+	".css", ".csv", ".htm", ".html", ".js", ".json", ".map", ".md", ".mjs",
+	".rss", ".svg", ".txt", ".webmanifest", ".xml",
+})
+
+func (c compression) compress(b []byte) ([]byte, error) {
+	return compressWith(c.newWriter, b)
+}
+
+func compressWith(newWriter func(io.Writer) io.WriteCloser, b []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	w := newWriter(buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// precompressions returns the configured compressions.
+func (s *Site) precompressions() (result []compression) {
+	for _, c := range compressions {
+		if utils.StringArrayContains(s.cfg.Precompress.Formats, c.name) {
+			result = append(result, c)
+		}
+	}
+	return
+}
+
+func (s *Site) precompressMinSize() int {
+	if n := s.cfg.Precompress.MinSize; n > 0 {
+		return n
+	}
+	return defaultPrecompressMinSize
+}
+
+// isCompressible returns true if an output file with this name and size
+// should be precompressed.
+func (s *Site) isCompressible(filename string, size int) bool {
+	return compressibleExts[strings.ToLower(filepath.Ext(filename))] && size >= s.precompressMinSize()
+}
+
+// isPrecompressedFile returns true if filename is the compressed variant of
+// another output file.
+func (s *Site) isPrecompressedFile(filename string) bool {
+	for _, c := range s.precompressions() {
+		if strings.HasSuffix(filename, c.ext) {
+			return compressibleExts[strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, c.ext)))]
+		}
+	}
+	return false
+}
+
+// ContentEncoding compresses a document's content for an HTTP response,
+// according to the precompress configuration and an Accept-Encoding request
+// header. It returns the compressed content and the Content-Encoding, or
+// the unmodified content and "" if the content shouldn't be compressed.
+//
+// The server uses this to serve the same variants as the precompressed
+// files. Since it compresses each response, it uses a fast compression level.
+func (s *Site) ContentEncoding(d Document, content []byte, acceptEncoding string) ([]byte, string, error) {
+	if !s.isCompressible(s.destFilename(d), len(content)) {
+		return content, "", nil
+	}
+	accepted := acceptedEncodings(acceptEncoding)
+	for _, c := range s.precompressions() {
+		if accepted[c.encoding] {
+			b, err := compressWith(c.fastWriter, content)
+			return b, c.encoding, err
+		}
+	}
+	return content, "", nil
+}
+
+// acceptedEncodings parses an Accept-Encoding header. It omits encodings
+// with a quality value of zero.
+func acceptedEncodings(header string) map[string]bool {
+	accepted := map[string]bool{}
+	for _, item := range strings.Split(header, ",") {
+		fields := strings.Split(item, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		accepted[name] = true
+		for _, param := range fields[1:] {
+			if q, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if n, err := strconv.ParseFloat(q, 64); err == nil && n == 0 {
+					delete(accepted, name)
+				}
+			}
+		}
+	}
+	return accepted
+}
+
+// writePrecompressedFiles writes compressed variants of the text output
+// files, alongside them. It leaves a variant alone if its content is
+// already up to date, and removes variants whose output file is gone.
+//
+// Files such as downloads/data.csv.gz that are themselves output files, or
+// that keep_files lists, are the user's; they are never written or removed.
+func (s *Site) writePrecompressedFiles(docs []Document) (count int, err error) {
+	cs := s.precompressions()
+	if len(cs) == 0 || s.cfg.DryRun {
+		return 0, nil
+	}
+	outputs := s.outputFilenames()
+	for _, d := range docs {
+		filename := s.destFilename(d)
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return count, err
+		}
+		for _, c := range cs {
+			if s.isUserFile(filename+c.ext, outputs) {
+				continue
+			}
+			if !s.isCompressible(filename, len(b)) {
+				// the file may have been compressible in a previous build
+				if err := os.Remove(filename + c.ext); err != nil && !os.IsNotExist(err) {
+					return count, err
+				}
+				continue
+			}
+			wrote, err := writePrecompressedFile(c, filename+c.ext, b)
+			if err != nil {
+				return count, err
+			}
+			if wrote {
+				count++
+			}
+		}
+	}
+	return count, s.removeStalePrecompressedFiles(outputs)
+}
+
+// outputFilenames returns the set of the destination filenames of the
+// site's output documents, including those that plugins generated.
+func (s *Site) outputFilenames() map[string]bool {
+	outputs := map[string]bool{}
+	for _, d := range append(s.OutputDocs(), s.generatedFiles()...) {
+		outputs[s.destFilename(d)] = true
+	}
+	return outputs
+}
+
+// isUserFile returns true if a file in the destination is an output file,
+// or is in keep_files, rather than a compressed variant that the build wrote.
+func (s *Site) isUserFile(filename string, outputs map[string]bool) bool {
+	return outputs[filename] || s.KeepFile(utils.MustRel(s.DestDir(), filename))
+}
+
+func writePrecompressedFile(c compression, filename string, content []byte) (bool, error) {
+	if f, err := os.Open(filename); err == nil {
+		r, err := c.newReader(f)
+		if err == nil {
+			var prev []byte
+			prev, err = io.ReadAll(r)
+			if err == nil && bytes.Equal(prev, content) {
+				return false, f.Close()
+			}
+		}
+		_ = f.Close()
+	}
+	b, err := c.compress(content)
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(filename, b, 0644)
+}
+
+// removeStalePrecompressedFiles removes the compressed variants of files
+// that are no longer in the destination.
+func (s *Site) removeStalePrecompressedFiles(outputs map[string]bool) error {
+	log := logger.Default()
+	return filepath.Walk(s.DestDir(), func(filename string, info os.FileInfo, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case info.IsDir() || !s.isPrecompressedFile(filename) || s.isUserFile(filename, outputs):
+			return nil
+		}
+		if _, err := os.Stat(utils.TrimExt(filename)); os.IsNotExist(err) {
+			if s.cfg.Verbose {
+				log.Info("rm %s", filename)
+			}
+			return os.Remove(filename)
+		}
+		return nil
+	})
+}
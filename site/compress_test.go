@@ -0,0 +1,94 @@
+package site
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptedEncodings(t *testing.T) {
+	require.Equal(t, map[string]bool{"gzip": true, "br": true}, acceptedEncodings("gzip, br"))
+	require.Equal(t, map[string]bool{"gzip": true}, acceptedEncodings("gzip;q=0.5, br;q=0"))
+}
+
+func TestSite_writePrecompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	large := "<p>" + strings.Repeat("text ", 500) + "</p>"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "large.html"), []byte("---\n---\n"+large), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.html"), []byte("---\n---\nsmall"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_config.yml"), []byte("precompress:\n  formats: [gzip, brotli]\n"), 0644))
+
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	_, err = s.Write()
+	require.NoError(t, err)
+
+	dest := s.DestDir()
+	require.FileExists(t, filepath.Join(dest, "large.html.br"))
+	require.NoFileExists(t, filepath.Join(dest, "small.html.gz"))
+	f, err := os.Open(filepath.Join(dest, "large.html.gz"))
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, large, string(b))
+
+	// An unchanged file's compressed variants aren't rewritten.
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dest, "large.html.gz"), old, old))
+	s, err = FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	_, err = s.Write()
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dest, "large.html.gz"))
+	require.NoError(t, err)
+	require.True(t, info.ModTime().Before(time.Now().Add(-time.Minute)))
+
+	// The server compresses the same documents.
+	d, found := s.URLPage("/large.html")
+	require.True(t, found)
+	enc, encoding, err := s.ContentEncoding(d, []byte(large), "gzip")
+	require.NoError(t, err)
+	require.Equal(t, "gzip", encoding)
+	require.False(t, bytes.Equal([]byte(large), enc))
+}
+
+func TestSite_writePrecompressedFiles_userFiles(t *testing.T) {
+	dir := t.TempDir()
+	large := strings.Repeat("text ", 500)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "downloads"), 0755))
+	// compressed files that the site ships, with and without their originals
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "downloads", "data.csv.gz"), []byte("archive"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "downloads", "other.txt"), []byte(large), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "downloads", "other.txt.gz"), []byte("archive"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "_config.yml"), []byte("precompress:\n  formats: [gzip]\n"), 0644))
+
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	dest := s.DestDir()
+	// a variant that a previous build wrote
+	require.NoError(t, os.MkdirAll(dest, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "removed.html.gz"), []byte("stale"), 0644))
+	_, err = s.Write()
+	require.NoError(t, err)
+
+	for _, name := range []string{"data.csv.gz", "other.txt.gz"} {
+		b, err := os.ReadFile(filepath.Join(dest, "downloads", name))
+		require.NoError(t, err)
+		require.Equal(t, "archive", string(b), name)
+	}
+	require.NoFileExists(t, filepath.Join(dest, "removed.html.gz"))
+}
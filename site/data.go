@@ -10,32 +10,53 @@ import (
 )
 
 func (s *Site) readDataFiles() error {
-	s.data = map[string]interface{}{}
 	dataDir := filepath.Join(s.SourceDir(), s.cfg.DataDir)
+	data, err := readDataDir(dataDir)
+	if err != nil {
+		return err
+	}
+	s.data = data
+	if s.cfg.IsMultilingual() {
+		// _data/<lang>/ overrides the default data.
+		localized, err := readDataDir(filepath.Join(dataDir, s.cfg.ActiveLanguage()))
+		if err != nil {
+			return err
+		}
+		for k, v := range localized {
+			s.data[k] = utils.MergeYAMLValues(s.data[k], v)
+		}
+	}
+	return nil
+}
+
+// readDataDir reads the data files in a directory, but not its
+// subdirectories. It returns an empty map if the directory doesn't exist.
+func readDataDir(dataDir string) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
 	files, err := os.ReadDir(dataDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil
+			return data, nil
 		}
-		return err
+		return nil, err
 	}
 	for _, f := range files {
 		if f.IsDir() {
 			continue
 		}
 		var (
-			filename  = filepath.Join(dataDir, f.Name())
-			basename  = utils.TrimExt(filepath.Base(f.Name()))
-			data, err = readDataFile(filename)
+			filename = filepath.Join(dataDir, f.Name())
+			basename = utils.TrimExt(filepath.Base(f.Name()))
+			d, err   = readDataFile(filename)
 		)
 		if err != nil {
-			return utils.WrapPathError(err, filename)
+			return nil, utils.WrapPathError(err, filename)
 		}
-		if data != nil {
-			s.data[basename] = data
+		if d != nil {
+			data[basename] = d
 		}
 	}
-	return nil
+	return data, nil
 }
 
 func readDataFile(filename string) (interface{}, error) {
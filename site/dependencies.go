@@ -11,7 +11,8 @@ import (
 var excludeFileRE = regexp.MustCompile(`^[#~]|^\..|~$`)
 
 // Exclude returns a boolean indicating that the site configuration excludes a file or directory.
-// It does not exclude top-level _underscore files and directories.
+// It does not exclude top-level _underscore files and directories, or the
+// _underscore collection directories inside collections_dir.
 func (s *Site) Exclude(siteRel string) bool {
 	for siteRel != "." {
 		dir, base := filepath.Dir(siteRel), filepath.Base(siteRel)
@@ -20,7 +21,7 @@ func (s *Site) Exclude(siteRel string) bool {
 			return false
 		case utils.MatchList(s.cfg.Exclude, siteRel):
 			return true
-		case dir != "." && base[0] == '_':
+		case dir != "." && base[0] == '_' && !s.isCollectionDir(siteRel):
 			return true
 		default:
 			if excludeFileRE.MatchString(base) {
@@ -32,6 +33,13 @@ func (s *Site) Exclude(siteRel string) bool {
 	return false
 }
 
+// isCollectionDir returns true if siteRel is an _underscore directory inside
+// collections_dir, such as _collections/_posts.
+func (s *Site) isCollectionDir(siteRel string) bool {
+	dir := filepath.Clean(s.cfg.CollectionsDir)
+	return dir != "." && filepath.Dir(siteRel) == dir && strings.HasPrefix(filepath.Base(siteRel), "_")
+}
+
 // RequiresFullReload returns true if a source file requires a full reload / rebuild.
 //
 // This is always true outside of incremental mode, since even a
@@ -45,6 +53,9 @@ func (s *Site) RequiresFullReload(paths []string) bool {
 		switch {
 		case s.cfg.IsConfigPath(path):
 			return true
+		case len(s.localized) > 0:
+			// a change can affect the builds for several languages
+			return true
 		case s.Exclude(path):
 			continue
 		case !s.cfg.Incremental:
@@ -1,6 +1,9 @@
 package site
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/osteele/gojekyll/config"
@@ -19,8 +22,39 @@ func TestSite_Reloaded(t *testing.T) {
 	require.NotEqual(t, s0, s1)
 }
 
+func TestSite_Reloaded_generatedFiles(t *testing.T) {
+	s, err := FromDirectory("testdata/site1", config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	s.AddGeneratedFile("/stale.png", []byte("content"))
+	s.cfg.Incremental = true
+	s, err = s.Reloaded([]string{})
+	require.NoError(t, err)
+	_, found := s.generatedFile("/stale.png")
+	require.False(t, found)
+}
+
 // func TestSite_processFilesEvent(t *testing.T) {
-// func TestSite_rebuild(t *testing.T) {
+
+func TestSite_rebuild_includeCached(t *testing.T) {
+	dir := writeTestSite(t, map[string]string{
+		"_config.yml":          "incremental: true\n",
+		"_includes/title.html": "{{ page.title }}",
+		"index.html":           "---\ntitle: One\n---\n{% include_cached title.html %}",
+	})
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	_, err = s.Write()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "index.html"), []byte("---\ntitle: Two\n---\n{% include_cached title.html %}"), 0644))
+	_, _, err = s.rebuild([]string{"index.html"})
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(dir, "_site", "index.html"))
+	require.NoError(t, err)
+	require.Equal(t, "Two", strings.TrimSpace(string(b)))
+}
 
 func TestSite_RequiresFullReload(t *testing.T) {
 	s := New(config.Flags{})
@@ -0,0 +1,364 @@
+package site
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/osteele/gojekyll/frontmatter"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/osteele/gojekyll/plugins"
+	"github.com/osteele/gojekyll/utils"
+	"github.com/osteele/liquid"
+)
+
+// A CompatibilityIssue is a feature that the site uses that gojekyll
+// doesn't emulate, or a problem that would make its build differ from
+// Jekyll's.
+type CompatibilityIssue struct {
+	Source   string `json:"source"` // file relative to the site; or the URL, if it has none
+	Line     int    `json:"line"`   // 0 if the issue isn't at a line
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (i CompatibilityIssue) String() string {
+	loc := i.Source
+	if i.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", i.Source, i.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", loc, i.Severity, i.Message, i.Rule)
+}
+
+// pluginTags maps the tags that Jekyll plugins define to their plugins.
+var pluginTags = map[string]string{
+	"asset":            "jekyll-assets",
+	"asset_path":       "jekyll-assets",
+	"avatar":           "jekyll-avatar",
+	"bibliography":     "jekyll-scholar",
+	"cite":             "jekyll-scholar",
+	"feed_meta":        "jekyll-feed",
+	"gist":             "jekyll-gist",
+	"img":              "jekyll_picture_tag",
+	"last_modified_at": "jekyll-last-modified-at",
+	"octicon":          "jekyll-octicons",
+	"picture":          "jekyll_picture_tag",
+	"seo":              "jekyll-seo-tag",
+	"twitter":          "jekyll-twitter-plugin",
+}
+
+// CheckCompatibility returns the features that the site uses that gojekyll
+// doesn't emulate, and problems that Jekyll would also have with it: Liquid
+// tags, filters, and syntax in pages, layouts, and includes; kramdown
+// syntax in Markdown pages; layouts that don't exist; documents that are
+// written to the same URL; URLs that differ only in case; and themes that
+// gojekyll doesn't load the way Jekyll does.
+func (s *Site) CheckCompatibility() ([]CompatibilityIssue, error) {
+	c := compatChecker{site: s, tags: map[string]bool{}, filters: map[string]bool{}}
+	if err := c.checkTheme(); err != nil {
+		return nil, err
+	}
+	for _, d := range s.docs {
+		p, ok := d.(Page)
+		if !ok || s.cfg.IsSASSPath(p.Source()) {
+			continue
+		}
+		if err := c.checkFile(p.Source(), p.FrontMatter(), s.cfg.IsMarkdown(p.Source())); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range s.renderer.TemplateDirs() {
+		err := filepath.Walk(dir, func(filename string, info os.FileInfo, err error) error {
+			switch {
+			case os.IsNotExist(err):
+				return nil
+			case err != nil:
+				return err
+			case info.IsDir():
+				return nil
+			}
+			return c.checkFile(filename, nil, false)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.checkRoutes()
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		a, b := c.issues[i], c.issues[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Line < b.Line
+	})
+	return c.issues, nil
+}
+
+// A compatChecker accumulates the issues, and caches which tags and
+// filters are defined.
+type compatChecker struct {
+	site    *Site
+	issues  []CompatibilityIssue
+	tags    map[string]bool
+	filters map[string]bool
+}
+
+func (c *compatChecker) add(source string, line int, severity, rule, format string, a ...interface{}) {
+	c.issues = append(c.issues, CompatibilityIssue{source, line, severity, rule, fmt.Sprintf(format, a...)})
+}
+
+// sourceName returns a filename relative to the site, if it is in the site.
+func (c *compatChecker) sourceName(filename string) string {
+	rel, err := filepath.Rel(c.site.AbsDir(), utils.MustAbs(filename))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+// checkFile checks a page, layout, or include. fm is a page's front matter,
+// including defaults; or nil, to use the file's.
+func (c *compatChecker) checkFile(filename string, fm map[string]interface{}, isMarkdown bool) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var (
+		source  = c.sourceName(filename)
+		keys    = frontmatter.KeyLines(src)
+		content = src
+		lineNo  = 1
+	)
+	// Only pages can have TOML or JSON front matter
+	alternates := fm != nil && pages.AllowsAlternateFrontMatter(&c.site.cfg, filename)
+	fileFM, err := frontmatter.Read(&content, &lineNo, alternates)
+	if err != nil {
+		c.add(source, 0, SeverityError, "front-matter", "%s", err)
+		return nil
+	}
+	if fm == nil {
+		fm = fileFM
+	}
+	if layout, ok := fm["layout"].(string); ok && layout != "" && layout != "none" && layout != "null" {
+		if !c.site.renderer.LayoutExists(layout) {
+			c.add(source, keys["layout"], SeverityError, "missing-layout", "layout %q doesn't exist", layout)
+		}
+	}
+	c.checkLiquid(source, content, lineNo)
+	if isMarkdown {
+		c.checkMarkdown(source, content, lineNo)
+	}
+	return nil
+}
+
+var (
+	// liquidMarkupMatcher matches a tag, with its name in the first group;
+	// or an object.
+	liquidMarkupMatcher = regexp.MustCompile(`(?s)\{%-?\s*(\w+).*?-?%\}|\{\{.*?\}\}`)
+	liquidStringMatcher = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	liquidFilterMatcher = regexp.MustCompile(`\|\s*(\w+)`)
+	liquidErrorPrefix   = regexp.MustCompile(`^Liquid error( \(line \d+\))?: `)
+)
+
+// checkLiquid reports undefined tags and filters, and Liquid syntax errors.
+func (c *compatChecker) checkLiquid(source string, src []byte, lineNo int) {
+	skipUntil := ""
+	for _, m := range liquidMarkupMatcher.FindAllSubmatchIndex(src, -1) {
+		var (
+			markup = string(src[m[0]:m[1]])
+			name   string
+			line   = lineNo + bytes.Count(src[:m[0]], []byte("\n"))
+		)
+		if m[2] >= 0 {
+			name = string(src[m[2]:m[3]])
+		}
+		switch {
+		case skipUntil != "":
+			if name == skipUntil {
+				skipUntil = ""
+			}
+			continue
+		case name == "raw" || name == "comment":
+			skipUntil = "end" + name
+		case name == "" || name == "else" || name == "elsif" || name == "when" || strings.HasPrefix(name, "end"):
+		case !c.isTagDefined(name):
+			c.add(source, line, SeverityError, "unknown-tag", "{%% %s %%}: %s", name, c.tagHint(name))
+		}
+		body := liquidStringMatcher.ReplaceAllString(markup, "")
+		for _, fm := range liquidFilterMatcher.FindAllStringSubmatch(body, -1) {
+			if !c.isFilterDefined(fm[1]) {
+				c.add(source, line, SeverityError, "unknown-filter", "%q: gojekyll doesn't define this filter", fm[1])
+			}
+		}
+	}
+	_, err := c.site.TemplateEngine().ParseTemplateLocation(src, "", lineNo)
+	if err != nil && !strings.Contains(err.Error(), "undefined tag") {
+		line := 0
+		if se, ok := err.(liquid.SourceError); ok {
+			line = se.LineNumber()
+		}
+		c.add(source, line, SeverityError, "liquid-syntax", "%s", liquidErrorPrefix.ReplaceAllString(err.Error(), ""))
+	}
+}
+
+func (c *compatChecker) isTagDefined(name string) bool {
+	if defined, found := c.tags[name]; found {
+		return defined
+	}
+	c.tags[name] = c.site.renderer.IsTagDefined(name)
+	return c.tags[name]
+}
+
+func (c *compatChecker) isFilterDefined(name string) bool {
+	if defined, found := c.filters[name]; found {
+		return defined
+	}
+	c.filters[name] = c.site.renderer.IsFilterDefined(name)
+	return c.filters[name]
+}
+
+// tagHint describes how to define an undefined tag.
+func (c *compatChecker) tagHint(name string) string {
+	plugin, found := pluginTags[name]
+	if !found {
+		return "gojekyll doesn't define this tag"
+	}
+	if _, emulated := plugins.Lookup(plugin); emulated {
+		return fmt.Sprintf("the %s plugin defines this tag; add it to plugins in _config.yml", plugin)
+	}
+	return fmt.Sprintf("the %s plugin defines this tag; gojekyll doesn't emulate this plugin", plugin)
+}
+
+var (
+	kramdownMarkupMatcher = regexp.MustCompile(`\{::?[^}\n]*\}`)
+	// gojekyll handles these; see renderers/markdown_toc.go
+	kramdownTOCMatcher = regexp.MustCompile(`^\{:\s*(toc|\.no_toc)\s*\}$`)
+	fenceMatcher       = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+)
+
+// checkMarkdown reports kramdown syntax that goldmark renders as text:
+// attribute lists, such as {: .class}, and extensions, such as
+// {::comment}.
+func (c *compatChecker) checkMarkdown(source string, src []byte, lineNo int) {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	fence := ""
+	for line := lineNo; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if m := fenceMatcher.FindStringSubmatch(text); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		for _, markup := range kramdownMarkupMatcher.FindAllString(text, -1) {
+			switch {
+			case kramdownTOCMatcher.MatchString(markup):
+			case strings.HasPrefix(markup, "{::"):
+				c.add(source, line, SeverityWarning, "kramdown-syntax", "%s: kramdown extensions aren't supported; this is rendered as text", markup)
+			default:
+				c.add(source, line, SeverityWarning, "kramdown-syntax", "%s: kramdown attribute lists aren't supported; this is rendered as text", markup)
+			}
+		}
+	}
+}
+
+var remoteThemeMatcher = regexp.MustCompile(`(?m)^remote_theme\s*:`)
+
+// gemspecDependencyMatcher matches the dependencies in a gemspec, such as
+// spec.add_runtime_dependency "jekyll-feed", "~> 0.9".
+var gemspecDependencyMatcher = regexp.MustCompile(`(?m)^\s*\w+\.add_(?:runtime_)?dependency\s*\(?\s*["']([^"']+)["']`)
+
+// checkTheme reports a remote_theme, which gojekyll doesn't download; and
+// the plugins that the theme's gem depends on, which Jekyll loads, but
+// gojekyll only loads if the site's plugins list names them.
+func (c *compatChecker) checkTheme() error {
+	s := c.site
+	for _, filename := range s.cfg.ConfigFiles() {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if loc := remoteThemeMatcher.FindIndex(b); loc != nil {
+			line := 1 + bytes.Count(b[:loc[0]], []byte("\n"))
+			c.add(c.sourceName(filename), line, SeverityError, "remote-theme",
+				"remote_theme: gojekyll doesn't download remote themes; the site is built without the theme's layouts, includes, and assets")
+		}
+	}
+	if s.themeDir == "" {
+		return nil
+	}
+	gemspecs, err := filepath.Glob(filepath.Join(s.themeDir, "*.gemspec"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range gemspecs {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		for _, m := range gemspecDependencyMatcher.FindAllSubmatchIndex(b, -1) {
+			name := string(b[m[2]:m[3]])
+			line := 1 + bytes.Count(b[:m[0]], []byte("\n"))
+			_, emulated := plugins.Lookup(name)
+			switch {
+			case emulated && !utils.StringArrayContains(s.plugins, name):
+				c.add(c.sourceName(filename), line, SeverityWarning, "theme-plugin",
+					"the %s theme depends on the %s plugin, which gojekyll only loads if it is in plugins in _config.yml", s.cfg.Theme, name)
+			case !emulated && strings.HasPrefix(name, "jekyll-"):
+				c.add(c.sourceName(filename), line, SeverityWarning, "theme-plugin",
+					"the %s theme depends on the %s plugin, which gojekyll doesn't emulate", s.cfg.Theme, name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRoutes reports documents that are written to the same URL, and URLs
+// that would be written to the same file on a case-insensitive file system.
+func (c *compatChecker) checkRoutes() {
+	s := c.site
+	docs := map[Document]bool{}
+	for _, d := range s.docs {
+		docs[d] = true
+	}
+	for u, shadowed := range s.shadowed {
+		d, found := s.Routes[u]
+		if !found {
+			continue
+		}
+		for _, prev := range shadowed {
+			// a multilingual site drops the translations that it doesn't use
+			if docs[prev] {
+				c.add(s.docSourceName(u, prev), 0, SeverityError, "url-conflict",
+					"%s is also written by %s; only one of them is written", u, s.docSourceName(u, d))
+			}
+		}
+	}
+	folded := map[string][]string{}
+	for u := range s.Routes {
+		k := strings.ToLower(u)
+		folded[k] = append(folded[k], u)
+	}
+	for _, urls := range folded {
+		if len(urls) < 2 {
+			continue
+		}
+		sort.Strings(urls)
+		for _, u := range urls[1:] {
+			c.add(s.docSourceName(u, s.Routes[u]), 0, SeverityWarning, "case-collision",
+				"%s and %s differ only in case; they are the same file on macOS and Windows", u, urls[0])
+		}
+	}
+}
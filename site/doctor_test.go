@@ -0,0 +1,65 @@
+package site
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_CheckCompatibility(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml":            "title: Site\n",
+		"_layouts/default.html":  "---\n---\n<head>{% seo %}</head>{{ content | no_such_filter }}",
+		"_includes/note.html":    "{% if include.text %}{{ include.text | upcase }}{% endif %}",
+		"_includes/json-ld.html": "{\n  \"name\": {{ site.title | jsonify }}\n}\n",
+		"index.md":               "---\nlayout: post\n---\n# Title\n{: .lead}\n\n```\n{: .literal}\n```\n{% raw %}{% tweet %}{% endraw %}{{ \"a | b\" | upcase }}\n{% if x %}",
+		"other.html":             "---\npermalink: /index.html\n---\n",
+		"About.html":             "---\n---\n",
+		"about.html":             "---\nlayout: default\n---\n",
+	})
+
+	issues, err := s.CheckCompatibility()
+	require.NoError(t, err)
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	require.ElementsMatch(t, []string{
+		"_layouts/default.html:3: error: {% seo %}: the jekyll-seo-tag plugin defines this tag; add it to plugins in _config.yml [unknown-tag]",
+		`_layouts/default.html:3: error: "no_such_filter": gojekyll doesn't define this filter [unknown-filter]`,
+		"about.html: warning: /about.html and /About.html differ only in case; they are the same file on macOS and Windows [case-collision]",
+		"index.md: error: /index.html is also written by other.html; only one of them is written [url-conflict]",
+		`index.md:2: error: layout "post" doesn't exist [missing-layout]`,
+		"index.md:5: warning: {: .lead}: kramdown attribute lists aren't supported; this is rendered as text [kramdown-syntax]",
+		`index.md:11: error: unterminated "if" block in {% if x %} [liquid-syntax]`,
+	}, messages)
+}
+
+func TestSite_CheckCompatibility_theme(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml": "title: Site\nremote_theme: owner/theme\nplugins: [jekyll-seo-tag]\n",
+	})
+	s.cfg.Theme = "theme"
+	s.themeDir = writeTestSite(t, map[string]string{
+		"theme.gemspec": "Gem::Specification.new do |spec|\n" +
+			"  spec.add_runtime_dependency \"jekyll\", \">= 3.5\"\n" +
+			"  spec.add_runtime_dependency \"jekyll-feed\", \"~> 0.9\"\n" +
+			"  spec.add_runtime_dependency \"jekyll-seo-tag\", \"~> 2.1\"\n" +
+			"  spec.add_runtime_dependency \"jekyll-archives\"\n" +
+			"end\n",
+	})
+
+	issues, err := s.CheckCompatibility()
+	require.NoError(t, err)
+	var messages []string
+	for _, issue := range issues {
+		messages = append(messages, fmt.Sprintf("%s:%d: %s [%s]", filepath.Base(issue.Source), issue.Line, issue.Message, issue.Rule))
+	}
+	require.ElementsMatch(t, []string{
+		"_config.yml:2: remote_theme: gojekyll doesn't download remote themes; the site is built without the theme's layouts, includes, and assets [remote-theme]",
+		"theme.gemspec:3: the theme theme depends on the jekyll-feed plugin, which gojekyll only loads if it is in plugins in _config.yml [theme-plugin]",
+		"theme.gemspec:5: the theme theme depends on the jekyll-archives plugin, which gojekyll doesn't emulate [theme-plugin]",
+	}, messages)
+}
@@ -30,6 +30,7 @@ func (s *Site) initializeDrop() error {
 		"collections":  s.collectionDrops(),
 		"data":         s.data,
 		"documents":    docs,
+		"env":          s.cfg.EnvVariables(),
 		"html_files":   s.htmlFiles(),
 		"html_pages":   s.htmlPages(),
 		"pages":        s.nonCollectionPages,
@@ -40,6 +41,10 @@ func (s *Site) initializeDrop() error {
 	for _, c := range s.Collections {
 		drop[c.Name] = c.Pages()
 	}
+	if s.cfg.IsMultilingual() {
+		drop["active_lang"] = s.cfg.ActiveLanguage()
+		drop["default_lang"] = s.cfg.DefaultLanguage()
+	}
 	s.drop = drop
 	s.setPostVariables()
 	return s.runHooks(func(h plugins.Plugin) error {
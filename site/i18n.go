@@ -0,0 +1,129 @@
+package site
+
+import (
+	"strings"
+
+	"github.com/osteele/gojekyll/utils"
+)
+
+// A multilingual site, modeled on jekyll-polyglot, is built once for each
+// language. The build for the default language is the site; its localized
+// field holds the builds for the other languages, whose pages are under
+// /<lang>/. Pages declare their language with a lang front matter variable;
+// where a page hasn't been translated into a language, that language's
+// build uses the page in the default language.
+
+// languageRank ranks a document for inclusion in the active language's
+// build: a document in the active language ranks above one in the default
+// language; one in another language is left out.
+func (s *Site) languageRank(d Document) int {
+	lang := s.cfg.DefaultLanguage()
+	if p, ok := d.(Page); ok {
+		if l, ok := p.FrontMatter()["lang"].(string); ok && l != "" {
+			lang = l
+		}
+	}
+	switch lang {
+	case s.cfg.ActiveLanguage():
+		return 2
+	case s.cfg.DefaultLanguage():
+		return 1
+	default:
+		return 0
+	}
+}
+
+// coordinateLanguages keeps, for each URL, the document in the active
+// language if there is one, and otherwise the one in the default language.
+// It sets the lang variable of pages that don't declare a language.
+func (s *Site) coordinateLanguages() {
+	if !s.cfg.IsMultilingual() {
+		return
+	}
+	best := map[string]Document{}
+	for _, d := range s.docs {
+		u := d.URL()
+		if b, found := best[u]; !found || s.languageRank(d) > s.languageRank(b) {
+			best[u] = d
+		}
+	}
+	keep := func(d Document) bool {
+		return s.languageRank(d) > 0 && best[d.URL()] == d
+	}
+	var docs []Document
+	for _, d := range s.docs {
+		if keep(d) {
+			docs = append(docs, d)
+		}
+	}
+	s.docs = docs
+	var ps []Page
+	for _, p := range s.nonCollectionPages {
+		if keep(p) {
+			ps = append(ps, p)
+		}
+	}
+	s.nonCollectionPages = ps
+	for _, c := range s.Collections {
+		c.FilterPages(func(p Page) bool { return keep(p) })
+	}
+	for u := range s.Routes {
+		if d, found := best[u]; found && keep(d) {
+			s.Routes[u] = d
+		} else {
+			delete(s.Routes, u)
+		}
+	}
+	for _, p := range s.Pages() {
+		if _, ok := p.FrontMatter()["lang"]; !ok {
+			p.FrontMatter()["lang"] = s.cfg.DefaultLanguage()
+		}
+	}
+}
+
+// readLocalizedSites reads the builds for the languages besides the
+// default, and adds their pages to the site's routes.
+//
+// Static files, and pages that are excluded from localization, are only
+// written by the default language's build.
+func (s *Site) readLocalizedSites() error {
+	s.localized = nil
+	if !s.cfg.IsMultilingual() || s.cfg.ActiveLanguage() != s.cfg.DefaultLanguage() {
+		return nil
+	}
+	for _, lang := range s.cfg.Languages {
+		if lang == s.cfg.DefaultLanguage() {
+			continue
+		}
+		ls := &Site{cfg: s.cfg, flags: s.flags}
+		ls.cfg.ActiveLang = lang
+		if err := ls.Read(); err != nil {
+			return utils.WrapError(err, "reading "+lang)
+		}
+		prefix := "/" + lang + "/"
+		for u, d := range ls.Routes {
+			if strings.HasPrefix(u, prefix) {
+				s.Routes[u] = d
+			}
+		}
+		s.localized = append(s.localized, ls)
+	}
+	return nil
+}
+
+// isLocalizedDoc returns true if d was added to the routes from the build
+// for another language.
+func (s *Site) isLocalizedDoc(u string, d Document) bool {
+	return s.docBuild(u, d) != s
+}
+
+// docBuild returns the build that d was added to the routes from: the build
+// for another language, or the site.
+func (s *Site) docBuild(u string, d Document) *Site {
+	for _, ls := range s.localized {
+		if ls.Routes[u] == d {
+			return ls
+		}
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package site
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_multilingual(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml":            "languages: [en, es, fr]\ndefault_lang: en\n",
+		"_data/strings.yml":      "hello: Hello\nbye: Bye\n",
+		"_data/es/strings.yml":   "hello: Hola\n",
+		"about.md":               "---\npermalink: /about/\n---\n{{ site.active_lang }} {{ page.lang }} {{ site.data.strings.hello }} {{ site.data.strings.bye }}",
+		"about.es.md":            "---\npermalink: /about/\nlang: es\n---\n{{ site.active_lang }} {{ page.lang }} {{ site.data.strings.hello }} {{ site.data.strings.bye }}",
+		"only-es.md":             "---\nlang: es\n---\n",
+		"_posts/2024-01-01-a.md": "---\n---\n",
+		"image.png":              "",
+	})
+
+	for _, u := range []string{"/about/", "/es/about/", "/fr/about/", "/es/only-es.html", "/2024/01/01/a.html", "/fr/2024/01/01/a.html", "/image.png"} {
+		require.Contains(t, s.Routes, u)
+	}
+	for _, u := range []string{"/only-es.html", "/fr/only-es.html", "/es/image.png"} {
+		require.NotContains(t, s.Routes, u)
+	}
+
+	render := func(u string) string {
+		buf := new(bytes.Buffer)
+		require.NoError(t, s.WriteDocument(buf, s.Routes[u]))
+		return buf.String()
+	}
+	require.Equal(t, "<p>en en Hello Bye</p>\n", render("/about/"))
+	require.Equal(t, "<p>es es Hola Bye</p>\n", render("/es/about/"))
+	require.Equal(t, "<p>fr en Hello Bye</p>\n", render("/fr/about/"))
+
+	url, found := s.FilenameURLPath("about.md")
+	require.True(t, found)
+	require.Equal(t, "/about/", url)
+}
+
+func TestSite_multilingual_plugins(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml": "languages: [en, fr]\ndefault_lang: en\nplugins: [jekyll-relative-links]\n",
+		"about.md":    "---\npermalink: /about/\n---\n",
+		"index.md":    "---\n---\n[About](about.md)",
+	})
+	render := func(u string) string {
+		buf := new(bytes.Buffer)
+		require.NoError(t, s.WriteDocument(buf, s.Routes[u]))
+		return buf.String()
+	}
+	// each language's build has its own plugin instances, with that build's
+	// URLs
+	require.Contains(t, render("/index.html"), `href="/about/"`)
+	require.Contains(t, render("/fr/index.html"), `href="/fr/about/"`)
+}
@@ -0,0 +1,291 @@
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/osteele/gojekyll/cache"
+	"github.com/osteele/gojekyll/utils"
+)
+
+// defaultLinkCheckTimeout is the timeout for requests to external links,
+// if LinkCheckOptions.Timeout isn't set.
+const defaultLinkCheckTimeout = 10 * time.Second
+
+// LinkCheckOptions configures CheckLinks.
+type LinkCheckOptions struct {
+	External bool          // also request links to other sites
+	Timeout  time.Duration // timeout for each external request
+}
+
+// A BrokenLink is a link, in a rendered page, that doesn't resolve.
+type BrokenLink struct {
+	Source string // the page's source file, relative to the site; or its URL if it has none
+	Output string // the page's output file, relative to the site
+	Line   int    // line number in the output file, not the source file
+	Link   string
+	Reason string
+}
+
+func (b BrokenLink) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s (in %s)", b.Output, b.Line, b.Link, b.Reason, b.Source)
+}
+
+// CheckLinks renders the site's HTML pages, and returns the links in them
+// whose targets aren't in the site. Internal links are resolved against the
+// site's routes, including their #fragment anchors. External links are only
+// requested if opts.External is set; their results are cached for a day.
+func (s *Site) CheckLinks(opts LinkCheckOptions) ([]BrokenLink, error) {
+	urls, rendered, err := s.renderHTMLDocs()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		broken   []BrokenLink
+		ids      = map[Document]map[string]bool{}
+		external = map[string][]BrokenLink{}
+	)
+	for _, u := range urls {
+		doc := s.Routes[u]
+		source, output := s.docSourceName(u, doc), s.docOutputName(doc)
+		for _, link := range utils.HTMLLinks(rendered[doc]) {
+			b := BrokenLink{Source: source, Output: output, Line: link.Line, Link: link.URL}
+			target, fragment, kind := s.resolveLink(u, link.URL)
+			switch kind {
+			case linkMalformed:
+				b.Reason = "malformed URL"
+			case linkOutsideBaseURL:
+				b.Reason = fmt.Sprintf("not under the baseurl %s; use the relative_url filter", s.cfg.BaseURL)
+			case linkExternal:
+				if opts.External {
+					external[target] = append(external[target], b)
+				}
+				continue
+			case linkInternal:
+				d, found := s.URLPage(target)
+				if !found {
+					b.Reason = "no such file"
+					break
+				}
+				if fragment == "" || fragment == "top" {
+					continue
+				}
+				page, ok := rendered[d]
+				if !ok {
+					continue
+				}
+				if ids[d] == nil {
+					ids[d] = utils.HTMLIDs(page)
+				}
+				if !ids[d][fragment] {
+					b.Reason = fmt.Sprintf("no #%s anchor", fragment)
+				}
+			default:
+				continue
+			}
+			if b.Reason != "" {
+				broken = append(broken, b)
+			}
+		}
+	}
+	for target, reason := range checkExternalLinks(keys(external), opts.Timeout) {
+		for _, b := range external[target] {
+			b.Reason = reason
+			broken = append(broken, b)
+		}
+	}
+	sort.SliceStable(broken, func(i, j int) bool {
+		a, b := broken[i], broken[j]
+		if a.Output != b.Output {
+			return a.Output < b.Output
+		}
+		return a.Line < b.Line
+	})
+	return broken, nil
+}
+
+type linkKind int
+
+const (
+	linkIgnored linkKind = iota // mailto:, tel:, data:, etc.
+	linkMalformed
+	linkInternal
+	linkExternal
+	linkOutsideBaseURL // a root-relative link that doesn't start with the baseurl
+)
+
+// resolveLink resolves a link in the page at URL path from. For an internal
+// link, it returns the target's URL path, with the baseurl removed, and its
+// unescaped fragment. For an external link, it returns the absolute URL
+// without its fragment. A link to a path on the site that isn't under the
+// baseurl, such as /about/ for /blog/about/, 404s once the site is deployed.
+func (s *Site) resolveLink(from, link string) (target, fragment string, kind linkKind) {
+	if link == "" {
+		return "", "", linkIgnored
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", "", linkMalformed
+	}
+	if u.Scheme == "" && u.Host != "" {
+		u.Scheme = "https"
+	}
+	switch u.Scheme {
+	case "":
+	case "http", "https":
+		site := strings.TrimSuffix(s.cfg.AbsoluteURL, "/")
+		if site == "" || !strings.HasPrefix(u.String()+"/", site+"/") {
+			u.Fragment = ""
+			return u.String(), "", linkExternal
+		}
+		u, err = url.Parse(strings.TrimPrefix(u.String(), site))
+		if err != nil {
+			return "", "", linkMalformed
+		}
+	default:
+		return "", "", linkIgnored
+	}
+	base := &url.URL{Path: from}
+	if strings.HasPrefix(u.Path, "/") {
+		if baseurl := strings.TrimSuffix(s.cfg.BaseURL, "/"); baseurl != "" {
+			switch {
+			case u.Path == baseurl:
+				u.Path = "/"
+			case strings.HasPrefix(u.Path, baseurl+"/"):
+				u.Path = strings.TrimPrefix(u.Path, baseurl)
+			default:
+				return u.Path, "", linkOutsideBaseURL
+			}
+		}
+	}
+	target = base.ResolveReference(u).Path
+	if target == "" {
+		target = from
+	}
+	return target, u.Fragment, linkInternal
+}
+
+// renderHTMLDocs renders the site's HTML pages into memory. It returns
+// their sorted URL paths, and a map from each document to its content.
+func (s *Site) renderHTMLDocs() ([]string, map[Document][]byte, error) {
+	if err := s.ensureRendered(); err != nil {
+		return nil, nil, err
+	}
+	rendered := map[Document][]byte{}
+	var urls []string
+	for u, d := range s.Routes {
+		if !isHTMLDoc(d) {
+			continue
+		}
+		buf := new(bytes.Buffer)
+		if err := s.WriteDocument(buf, d); err != nil {
+			return nil, nil, err
+		}
+		rendered[d] = buf.Bytes()
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls, rendered, nil
+}
+
+// docSourceName returns the name of a document's source file relative to
+// the site, for reports; or its URL path if it isn't read from a file.
+func (s *Site) docSourceName(u string, d Document) string {
+	if src := d.Source(); src != "" {
+		return utils.MustRel(s.SourceDir(), src)
+	}
+	return u
+}
+
+// docOutputName returns the name of a document's output file relative to the
+// site, for reports. Line numbers in reports refer to this file, since they
+// are found in the rendered page.
+func (s *Site) docOutputName(d Document) string {
+	return utils.MustRel(s.SourceDir(), s.destFilename(d))
+}
+
+// isHTMLDoc returns true if the document is rendered to an HTML page.
+func isHTMLDoc(d Document) bool {
+	if d.IsStatic() {
+		return false
+	}
+	switch strings.ToLower(path.Ext(d.URL())) {
+	case "", ".html", ".htm":
+		return true
+	}
+	return false
+}
+
+// checkExternalLinks requests each URL, and returns a map from the URLs that
+// failed to the reason they failed.
+func checkExternalLinks(urls []string, timeout time.Duration) map[string]string {
+	if timeout == 0 {
+		timeout = defaultLinkCheckTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	var (
+		mx     sync.Mutex
+		wg     sync.WaitGroup
+		failed = map[string]string{}
+		sem    = make(chan bool, 8)
+	)
+	// Successful requests are cached for the day; failures are re-tried.
+	header := "check-links " + time.Now().Format("2006-01-02")
+	for _, u := range urls {
+		wg.Add(1)
+		go func(u string) {
+			defer wg.Done()
+			sem <- true
+			defer func() { <-sem }()
+			_, err := cache.WithFile(header, u, func() (string, error) {
+				return "ok", requestExternalLink(client, u)
+			})
+			if err != nil {
+				mx.Lock()
+				failed[u] = err.Error()
+				mx.Unlock()
+			}
+		}(u)
+	}
+	wg.Wait()
+	return failed
+}
+
+// requestExternalLink tries a HEAD request, and falls back to GET for
+// servers that don't implement HEAD.
+func requestExternalLink(client *http.Client, u string) error {
+	var status int
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", "gojekyll check-links")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		status = resp.StatusCode
+		if status < 400 {
+			return nil
+		}
+	}
+	return fmt.Errorf("HTTP %d", status)
+}
+
+func keys(m map[string][]BrokenLink) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
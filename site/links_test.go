@@ -0,0 +1,30 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_CheckLinks(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml":     "baseurl: /blog\nurl: https://example.com\n",
+		"index.html":      "---\n---\n<a href=\"about/\">ok</a>\n<a href=\"/blog/about/#team\">ok</a>\n<a href=\"missing.html\">missing</a>\n<a href=\"/blogroll/\">outside</a>\n<a href=\"/about/\">no baseurl</a>",
+		"about.md":        "---\npermalink: /about/\n---\n<h2 id=\"team\">Team</h2>\n\n[bad anchor](#staff) [mail](mailto:me@example.com) [site](https://example.com/blog/style.css)",
+		"style.css":       "body {}",
+		"other/page.html": "---\n---\n<img src=\"../style.css\"><a href=\"https://example.org/\">external</a>",
+	})
+
+	broken, err := s.CheckLinks(LinkCheckOptions{})
+	require.NoError(t, err)
+	var messages []string
+	for _, b := range broken {
+		messages = append(messages, b.String())
+	}
+	require.Equal(t, []string{
+		"_site/about/index.html:2: #staff: no #staff anchor (in about.md)",
+		"_site/index.html:3: missing.html: no such file (in index.html)",
+		"_site/index.html:4: /blogroll/: not under the baseurl /blog; use the relative_url filter (in index.html)",
+		"_site/index.html:5: /about/: not under the baseurl /blog; use the relative_url filter (in index.html)",
+	}, messages)
+}
@@ -10,32 +10,86 @@ import (
 	"github.com/osteele/liquid"
 	"github.com/tdewolff/minify"
 	"github.com/tdewolff/minify/html"
+	"github.com/tdewolff/minify/json"
+	"github.com/tdewolff/minify/xml"
 )
 
 // AddHTMLPage is in the plugins.Site interface.
+//
+// The template's page variable holds fm, and the page's url.
 func (s *Site) AddHTMLPage(url string, src string, fm pages.FrontMatter) {
 	tpl, err := s.TemplateEngine().ParseTemplate([]byte(src))
 	if err != nil {
 		panic(err)
 	}
-	d := &templateDoc{pages.PageEmbed{Path: url}, s, tpl}
+	d := &templateDoc{pages.PageEmbed{Path: url}, s, tpl, fm}
 	s.AddDocument(d, true)
 }
 
+// AddGeneratedFile is in the plugins.Site interface.
+//
+// Unlike AddHTMLPage, it is safe to call while the site is being rendered or
+// written. Generated files are kept out of Routes, and are written after the
+// site's other documents.
+func (s *Site) AddGeneratedFile(url string, content []byte) {
+	s.generatedMx.Lock()
+	defer s.generatedMx.Unlock()
+	if s.generated == nil {
+		s.generated = map[string]Document{}
+	}
+	s.generated[url] = &generatedFile{pages.PageEmbed{Path: url}, content}
+}
+
+func (s *Site) generatedFile(url string) (Document, bool) {
+	s.generatedMx.Lock()
+	defer s.generatedMx.Unlock()
+	d, found := s.generated[url]
+	if !found {
+		for _, ls := range s.localized {
+			if d, found = ls.generatedFile(url); found {
+				break
+			}
+		}
+	}
+	return d, found
+}
+
+func (s *Site) generatedFiles() []Document {
+	s.generatedMx.Lock()
+	defer s.generatedMx.Unlock()
+	out := make([]Document, 0, len(s.generated))
+	for _, d := range s.generated {
+		out = append(out, d)
+	}
+	// Files that the other languages' builds generated, such as image
+	// variants, unless this build generated the same URL.
+	for _, ls := range s.localized {
+		for _, d := range ls.generatedFiles() {
+			if _, found := s.generated[d.URL()]; !found {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
 func (s *Site) installPlugins() error {
 	s.plugins = s.cfg.Plugins
+	s.pluginInstances = map[string]plugins.Plugin{}
 	installed := utils.StringSet{}
 	// Install plugins and call their ModifyPluginList methods.
 	// Repeat until no plugins have been added.
 	for len(s.plugins) > len(installed) {
 		// Collect plugins into a list instead of map, in order to preserve order
 		pending := utils.StringList(s.plugins).Reject(installed.Contains)
-		if err := plugins.Install(pending, s); err != nil {
+		instances, err := plugins.Install(pending, s)
+		if err != nil {
 			return err
 		}
 		for _, name := range pending {
-			p, ok := plugins.Lookup(name)
+			p, ok := instances[name]
 			if ok {
+				s.pluginInstances[name] = p
 				s.plugins = p.ModifyPluginList(s.plugins)
 			}
 		}
@@ -46,7 +100,7 @@ func (s *Site) installPlugins() error {
 
 func (s *Site) runHooks(h func(plugins.Plugin) error) error {
 	for _, name := range s.plugins {
-		p, ok := plugins.Lookup(name)
+		p, ok := s.pluginInstances[name]
 		if ok {
 			if err := h(p); err != nil {
 				return utils.WrapError(err, "running plugin")
@@ -60,18 +114,35 @@ type templateDoc struct {
 	pages.PageEmbed
 	site *Site
 	tpl  *liquid.Template
+	fm   pages.FrontMatter
 }
 
 func (d *templateDoc) Content() string {
-	bindings := map[string]interface{}{"site": d.site}
+	page := map[string]interface{}{}
+	for k, v := range d.fm {
+		page[k] = v
+	}
+	page["url"] = d.URL()
+	bindings := map[string]interface{}{"site": d.site, "page": page, "jekyll": pages.JekyllVariable()}
 	b, err := d.tpl.Render(bindings)
 	if err != nil {
 		panic(err)
 	}
+	// Minify XML output, such as feeds and sitemaps, as XML; the HTML
+	// minifier drops its declaration and unquotes its attributes.
+	mediaType := "text/html"
+	switch d.OutputExt() {
+	case ".xml":
+		mediaType = "text/xml"
+	case ".json":
+		mediaType = "application/json"
+	}
 	m := minify.New()
 	m.AddFunc("text/html", html.Minify)
+	m.AddFunc("text/xml", xml.Minify)
+	m.AddFunc("application/json", json.Minify)
 	min := bytes.NewBuffer(make([]byte, 0, len(b)))
-	if err := m.Minify("text/html", min, bytes.NewBuffer(b)); err != nil {
+	if err := m.Minify(mediaType, min, bytes.NewBuffer(b)); err != nil {
 		panic(err)
 	}
 	return min.String()
@@ -81,3 +152,14 @@ func (d *templateDoc) Write(w io.Writer) error {
 	_, err := io.WriteString(w, d.Content())
 	return err
 }
+
+// A generatedFile is a file whose content was computed by a plugin.
+type generatedFile struct {
+	pages.PageEmbed
+	content []byte
+}
+
+func (d *generatedFile) Write(w io.Writer) error {
+	_, err := w.Write(d.content)
+	return err
+}
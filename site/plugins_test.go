@@ -0,0 +1,154 @@
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_titlesFromHeadings(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml": "plugins: [jekyll-optional-front-matter, jekyll-titles-from-headings]\n" +
+			"titles_from_headings:\n  strip_title: true\n",
+		"guide.md": "# Project *Name*\n\nAbout the project.\n",
+		"page.md":  "---\ntitle: Declared\n---\n# Heading\n",
+	})
+
+	guide, found := s.FilePathPage("guide.md")
+	require.True(t, found)
+	require.Equal(t, "Project Name", guide.(Page).FrontMatter()["title"])
+	buf := new(bytes.Buffer)
+	require.NoError(t, guide.Write(buf))
+	require.Equal(t, "<p>About the project.</p>\n", strings.TrimSpace(buf.String())+"\n")
+
+	page, found := s.FilePathPage("page.md")
+	require.True(t, found)
+	require.Equal(t, "Declared", page.(Page).FrontMatter()["title"])
+}
+
+func TestSite_feeds(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml": "title: Blog\nurl: https://example.com\nbaseurl: /blog\n" +
+			"plugins: [jekyll-feed]\ncollections: [docs]\n" +
+			"feed:\n  path: atom.xml\n  posts_limit: 1\n  excerpt_only: true\n" +
+			"  categories: [news]\n  collections: [docs]\n  tags: {except: [skip]}\n",
+		"_posts/2020-01-01-first.md":  "---\ncategories: news\ntags: [go, skip]\n---\nFirst body\n",
+		"_posts/2020-01-02-second.md": "---\ntags: [go, two words]\n---\nSecond body\n",
+		"_docs/guide.md":              "---\ntitle: Guide\n---\nGuide body\n",
+		"feed.xslt.xml":               "<xsl/>",
+		"index.html":                  "---\n---\n{% feed_meta %}",
+	})
+
+	render := func(url string) string {
+		d, found := s.URLPage(url)
+		require.True(t, found, url)
+		buf := new(bytes.Buffer)
+		require.NoError(t, d.Write(buf))
+		return buf.String()
+	}
+	for _, url := range []string{"/feed.xml", "/feed/by_tag/skip.xml", "/feed/by_tag/two words.xml"} {
+		_, found := s.URLPage(url)
+		require.False(t, found, url)
+	}
+
+	feed := render("/atom.xml")
+	require.Contains(t, feed, `<?xml-stylesheet type="text/xml" href="https://example.com/blog/feed.xslt.xml"?>`)
+	require.Contains(t, feed, "Second")
+	require.NotContains(t, feed, "First")
+	require.NotContains(t, feed, "<content")
+
+	feed = render("/feed/news.xml")
+	require.Contains(t, feed, "Blog | News")
+	require.Contains(t, feed, "First")
+
+	feed = render("/feed/by_tag/go.xml")
+	require.Contains(t, feed, "Second")
+
+	feed = render("/feed/docs.xml")
+	require.Contains(t, feed, "Blog | Docs")
+	require.Contains(t, feed, "Guide")
+
+	require.Contains(t, render("/"), `<link type="application/atom+xml" rel="alternate" href="https://example.com/blog/atom.xml" title="Blog" />`)
+}
+
+func TestSite_feedFormats(t *testing.T) {
+	s := readTestSite(t, map[string]string{
+		"_config.yml": "title: Blog\nurl: https://example.com\nbaseurl: /blog\n" +
+			"plugins: [jekyll-feed]\n" +
+			"feed:\n  formats: [atom, rss, json]\n  categories: [news]\n",
+		"_posts/2020-01-01-first.md":  "---\ncategories: news\n---\nFirst \"body\"\n",
+		"_posts/2020-01-02-second.md": "---\ntags: [go]\nexcerpt: Summary\nfeed:\n  excerpt_only: true\n---\nSecond body\n",
+		"index.html":                  "---\n---\n{% feed_meta %}",
+	})
+	require.NoError(t, s.ensureRendered())
+
+	render := func(url string) string {
+		d, found := s.URLPage(url)
+		require.True(t, found, url)
+		buf := new(bytes.Buffer)
+		require.NoError(t, d.Write(buf))
+		return buf.String()
+	}
+	rss := render("/feed.rss.xml")
+	require.Contains(t, rss, `<rss version="2.0"`)
+	require.Contains(t, rss, "<link>https://example.com/blog/2020/01/02/second.html</link>")
+	require.Contains(t, rss, "<pubDate>Thu, 02 Jan 2020 00:00:00 +0000</pubDate>")
+	require.Contains(t, render("/feed/news.rss.xml"), "<title>Blog | News</title>")
+
+	var feed struct {
+		Version string
+		Title   string
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			ID          string
+			ContentHTML string `json:"content_html"`
+			Tags        []string
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(render("/feed.json")), &feed))
+	require.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+	require.Equal(t, "Blog", feed.Title)
+	require.Equal(t, "https://example.com/blog/feed.json", feed.FeedURL)
+	require.Len(t, feed.Items, 2)
+	require.Equal(t, "https://example.com/blog/2020/01/02/second.html", feed.Items[0].ID)
+	require.Equal(t, "Summary", feed.Items[0].ContentHTML)
+	require.Equal(t, []string{"go"}, feed.Items[0].Tags)
+	require.Equal(t, "<p>First &ldquo;body&rdquo;</p>", feed.Items[1].ContentHTML)
+
+	index := render("/")
+	require.Contains(t, index, `<link type="application/rss+xml" rel="alternate" href="https://example.com/blog/feed.rss.xml" title="Blog" />`)
+	require.Contains(t, index, `<link type="application/feed+json" rel="alternate" href="https://example.com/blog/feed.json" title="Blog" />`)
+}
+
+func TestSite_lastModifiedAt(t *testing.T) {
+	files := map[string]string{
+		"_config.yml": "plugins: [jekyll-last-modified-at, jekyll-sitemap]\n" +
+			"last_modified_at:\n  date_format: \"%Y-%m-%d\"\n",
+		"index.html": "---\n---\n{% last_modified_at %}|{% last_modified_at %B %Y %}",
+	}
+	dir := writeTestSite(t, files)
+	mtime := time.Date(2021, 6, 7, 8, 9, 10, 0, time.Local)
+	for name := range files {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, name), mtime, mtime))
+	}
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+
+	render := func(url string) string {
+		d, found := s.URLPage(url)
+		require.True(t, found, url)
+		buf := new(bytes.Buffer)
+		require.NoError(t, d.Write(buf))
+		return buf.String()
+	}
+	require.Equal(t, "2021-06-07|June 2021", strings.TrimSpace(render("/")))
+	require.Contains(t, render("/sitemap.xml"), "<lastmod>2021-06-07T08:09:10")
+}
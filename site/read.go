@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/osteele/gojekyll/collection"
 	"github.com/osteele/gojekyll/config"
@@ -18,7 +19,7 @@ import (
 func FromDirectory(dir string, flags config.Flags) (*Site, error) {
 	s := New(flags)
 	if flags.ConfigFile != "" {
-		if err := s.cfg.FromFile(flags.ConfigFile); err != nil {
+		if err := s.cfg.FromFiles(config.SplitConfigFiles(flags.ConfigFile)); err != nil {
 			return nil, utils.WrapError(err, "reading site")
 		}
 		// Set source directory explicitly when using custom config
@@ -34,10 +35,17 @@ func FromDirectory(dir string, flags config.Flags) (*Site, error) {
 
 // Read loads the site data and files.
 func (s *Site) Read() error {
+	// The pages are read again, so they are rendered again; and the files
+	// that plugins generated from their previous contents are dropped.
+	s.renderOnce = sync.Once{}
+	s.generatedMx.Lock()
+	s.generated = nil
+	s.generatedMx.Unlock()
 	if err := s.installPlugins(); err != nil {
 		return utils.WrapError(err, "initializing plugins")
 	}
 	s.Routes = make(map[string]Document)
+	s.shadowed = make(map[string][]Document)
 	if err := s.findTheme(); err != nil {
 		return utils.WrapError(err, "finding theme")
 	}
@@ -53,6 +61,10 @@ func (s *Site) Read() error {
 	if err := s.ReadCollections(); err != nil {
 		return utils.WrapError(err, "reading collections")
 	}
+	s.coordinateLanguages()
+	if err := s.validateFrontMatter(); err != nil {
+		return utils.WrapError(err, "validating front matter")
+	}
 	if err := s.initializeRenderers(); err != nil {
 		return utils.WrapError(err, "initializing renderers")
 	}
@@ -64,7 +76,10 @@ func (s *Site) Read() error {
 			return err
 		}
 	}
-	return s.runHooks(func(p plugins.Plugin) error { return p.PostReadSite(s) })
+	if err := s.runHooks(func(p plugins.Plugin) error { return p.PostReadSite(s) }); err != nil {
+		return err
+	}
+	return s.readLocalizedSites()
 }
 
 // readFiles scans the source directory and creates pages and collection.
@@ -78,6 +93,9 @@ func (s *Site) readFiles(dir, base string) error {
 		switch {
 		case info.IsDir() && s.Exclude(rel):
 			return filepath.SkipDir
+		case info.IsDir() && s.isCollectionDir(rel):
+			// ReadCollections reads these
+			return filepath.SkipDir
 		case info.IsDir():
 			return nil
 		case s.Exclude(rel):
@@ -96,7 +114,7 @@ func (s *Site) readFiles(dir, base string) error {
 			return nil
 		}
 		s.diag.FilesFound++
-		defaultFrontmatter := s.cfg.GetFrontMatterDefaults("", rel)
+		defaultFrontmatter := s.cfg.GetFrontMatterDefaults(config.PagesType, rel)
 		d, err := pages.NewFile(s, filename, filepath.ToSlash(rel), defaultFrontmatter)
 		if err != nil {
 			return utils.WrapPathError(err, filename)
@@ -121,6 +139,9 @@ func (s *Site) AddDocument(d Document, output bool) {
 	if d.Published() || s.cfg.Unpublished {
 		s.docs = append(s.docs, d)
 		if output {
+			if prev, found := s.Routes[d.URL()]; found && prev != d {
+				s.shadowed[d.URL()] = append(s.shadowed[d.URL()], prev)
+			}
 			s.Routes[d.URL()] = d
 		}
 	} else {
@@ -70,7 +70,12 @@ func (s *Site) rebuild(paths []string) (r *Site, n int, err error) {
 		return
 	}
 	r = s
+	// the changed pages can change the output of include_cached tags
+	if s.renderer != nil {
+		s.renderer.ClearCaches()
+	}
 	pathSet := utils.MakeStringSet(paths)
+	var written []Document
 	for _, d := range s.docs {
 		if s.invalidatesDoc(pathSet, d) {
 			err = d.Reload()
@@ -81,8 +86,15 @@ func (s *Site) rebuild(paths []string) (r *Site, n int, err error) {
 			if err != nil {
 				return
 			}
+			written = append(written, d)
 			n++
 		}
 	}
+	count, err := s.writeGeneratedFiles()
+	if err != nil {
+		return
+	}
+	n += count
+	_, err = s.writePrecompressedFiles(append(written, s.generatedFiles()...))
 	return
 }
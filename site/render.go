@@ -19,6 +19,11 @@ func (s *Site) render() error {
 			errs = append(errs, err)
 		}
 	}
+	for _, ls := range s.localized {
+		if err := ls.ensureRendered(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	return combineErrors(errs)
 }
 
@@ -0,0 +1,140 @@
+package site
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/osteele/gojekyll/frontmatter"
+	"github.com/osteele/gojekyll/logger"
+	"github.com/osteele/gojekyll/pages"
+	"github.com/osteele/gojekyll/utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// schemasDir holds front matter schemas, named e.g. posts.yml, pages.json,
+// or <collection>.yml.
+const schemasDir = "_schemas"
+
+// pagesSchemaName is the schema name for pages that aren't in a collection.
+const pagesSchemaName = "pages"
+
+// readFrontMatterSchemas returns the front matter schemas, indexed by
+// collection name or "pages". A collection's schema can be declared in its
+// _config.yml collection metadata, under schema; a file in _schemas replaces
+// it.
+func (s *Site) readFrontMatterSchemas() (map[string]*frontmatter.Schema, error) {
+	schemas := map[string]*frontmatter.Schema{}
+	for name, data := range s.cfg.Collections {
+		if data["schema"] == nil {
+			continue
+		}
+		b, err := yaml.Marshal(data["schema"])
+		if err != nil {
+			return nil, err
+		}
+		schema, err := frontmatter.ParseSchema(b)
+		if err != nil {
+			return nil, fmt.Errorf("collections.%s.schema: %w", name, err)
+		}
+		schemas[name] = schema
+	}
+	dir := filepath.Join(s.SourceDir(), schemasDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		switch ext {
+		case ".yml", ".yaml", ".json":
+		default:
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := frontmatter.ParseSchema(b)
+		if err != nil {
+			return nil, utils.WrapPathError(err, filename)
+		}
+		schemas[strings.TrimSuffix(entry.Name(), ext)] = schema
+	}
+	return schemas, nil
+}
+
+// validateFrontMatter checks the front matter of the site's pages against
+// their schemas. Violations are logged as warnings; or, if
+// strict_front_matter is set, returned as errors.
+func (s *Site) validateFrontMatter() error {
+	schemas, err := s.readFrontMatterSchemas()
+	if err != nil || len(schemas) == 0 {
+		return err
+	}
+	type violation struct {
+		rel  string
+		line int
+		err  frontmatter.SchemaError
+	}
+	var violations []violation
+	for _, d := range s.docs {
+		p, ok := d.(Page)
+		if !ok || p.Source() == "" {
+			continue
+		}
+		name := pagesSchemaName
+		if c, ok := p.FrontMatter()["collection"].(string); ok {
+			name = c
+		}
+		schema := schemas[name]
+		if schema == nil {
+			continue
+		}
+		b, err := os.ReadFile(p.Source())
+		if err != nil {
+			return err
+		}
+		source := b
+		declared, err := frontmatter.Read(&source, nil, pages.AllowsAlternateFrontMatter(&s.cfg, p.Source()))
+		if err != nil {
+			return utils.WrapPathError(err, p.Source())
+		}
+		errs := schema.Validate(p.FrontMatter(), declared)
+		if len(errs) == 0 {
+			continue
+		}
+		lines := frontmatter.KeyLines(b)
+		rel := utils.MustRel(s.SourceDir(), p.Source())
+		for _, err := range errs {
+			line := lines[err.Key]
+			if line == 0 {
+				// e.g. a missing required variable
+				line = 1
+			}
+			violations = append(violations, violation{rel, line, err})
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.rel != b.rel {
+			return a.rel < b.rel
+		}
+		return a.line < b.line
+	})
+	errs := make([]error, len(violations))
+	for i, v := range violations {
+		errs[i] = fmt.Errorf("%s:%d: front matter %s", v.rel, v.line, v.err)
+	}
+	if s.cfg.StrictFrontMatter {
+		return combineErrors(errs)
+	}
+	log := logger.Default()
+	for _, err := range errs {
+		log.Warn("%s", err)
+	}
+	return nil
+}
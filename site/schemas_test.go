@@ -0,0 +1,30 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/osteele/gojekyll/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_validateFrontMatter(t *testing.T) {
+	dir := writeTestSite(t, map[string]string{
+		"_config.yml":            "collections:\n  posts:\n    schema:\n      required: [author]\n",
+		"_schemas/pages.yml":     "additionalProperties: false\nproperties:\n  title: {type: string}\n  layout: {type: string}\n",
+		"about.md":               "---\ntitle: About\nlayuot: page\n---\n",
+		"_posts/2024-01-01-a.md": "---\nlayout: post\n---\n",
+		"_posts/2024-01-02-b.md": "---\nauthor: B\n---\n",
+	})
+
+	s, err := FromDirectory(dir, config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+
+	strict := true
+	s, err = FromDirectory(dir, config.Flags{StrictFrontMatter: &strict})
+	require.NoError(t, err)
+	err = s.Read()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "_posts/2024-01-01-a.md:1: front matter author: required variable is missing")
+	require.Contains(t, err.Error(), `about.md:3: front matter layuot: unknown variable (did you mean "layout"?)`)
+}
@@ -27,8 +27,12 @@ type Site struct {
 	plugins  []string               // initially cfg.Plugins, but plugins can modify this this
 	themeDir string                 // absolute path to theme directory
 
+	pluginInstances map[string]plugins.Plugin // this site's instances of the installed plugins
+
 	docs               []Document // all documents, whether or not they are output
 	nonCollectionPages []Page
+	localized          []*Site               // builds for the languages besides the default
+	shadowed           map[string][]Document // URL path -> documents whose route a later document took
 
 	renderer   *renderers.Manager
 	renderOnce sync.Once
@@ -37,6 +41,9 @@ type Site struct {
 	dropOnce sync.Once
 	dropErr  error // error from initializeDrop, if any
 
+	generated   map[string]Document // URL path -> file added by a plugin during rendering
+	generatedMx sync.Mutex
+
 	// Build diagnostics
 	diag BuildDiagnostics
 }
@@ -148,6 +155,9 @@ func (s *Site) SetAbsoluteURL(url string) {
 	if s.drop != nil {
 		s.drop["url"] = url
 	}
+	for _, ls := range s.localized {
+		ls.SetAbsoluteURL(url)
+	}
 }
 
 // FilenameURLs returns a map of site-relative pathnames to URL paths
@@ -171,8 +181,8 @@ func (s *Site) FilePathPage(rel string) (Document, bool) {
 	rel = filepath.FromSlash(rel)
 
 	// This looks wasteful. If it shows up as a hotspot, you know what to do.
-	for _, p := range s.Routes {
-		if p.Source() != "" {
+	for u, p := range s.Routes {
+		if p.Source() != "" && !s.isLocalizedDoc(u, p) {
 			if r, err := filepath.Rel(s.SourceDir(), p.Source()); err == nil {
 				if r == rel {
 					return p, true
@@ -214,9 +224,10 @@ func (s *Site) initializeRenderers() (err error) {
 	if err != nil {
 		return err
 	}
-	engine := s.renderer.TemplateEngine()
-	return s.runHooks(func(p plugins.Plugin) error {
-		return p.ConfigureTemplateEngine(engine)
+	return s.renderer.ConfigureTemplateEngines(func(e *liquid.Engine) error {
+		return s.runHooks(func(p plugins.Plugin) error {
+			return p.ConfigureTemplateEngine(e)
+		})
 	})
 }
 
@@ -253,5 +264,8 @@ func (s *Site) URLPage(urlpath string) (p Document, found bool) {
 		// Try with trailing slash for directory-style permalinks
 		p, found = s.Routes[urlpath+"/"]
 	}
+	if !found {
+		p, found = s.generatedFile(urlpath)
+	}
 	return
 }
@@ -1,6 +1,8 @@
 package site
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/osteele/gojekyll/config"
@@ -38,9 +40,57 @@ func TestExclude(t *testing.T) {
 	require.True(t, s.Exclude("_posts/_file"))
 	require.True(t, s.Exclude("_posts/_dir/file"))
 
+	s.cfg.CollectionsDir = "my_collections"
+	require.False(t, s.Exclude("my_collections/_posts/file"))
+	require.True(t, s.Exclude("my_collections/_posts/_file"))
+	require.True(t, s.Exclude("dir/_posts/file"))
+
 	// The following aren't documented but are evident
 	// TODO submit a doc PR to Jekyll
 	require.True(t, s.Exclude("#file"))
 	require.True(t, s.Exclude("~file"))
 	require.True(t, s.Exclude("file~"))
 }
+
+// writeTestSite writes a site's files, from their paths to their contents,
+// into a temporary directory, and returns the directory.
+func writeTestSite(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+		require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	}
+	return dir
+}
+
+// readTestSite writes a site's files into a temporary directory, and reads
+// the site.
+func readTestSite(t *testing.T, files map[string]string) *Site {
+	s, err := FromDirectory(writeTestSite(t, files), config.Flags{})
+	require.NoError(t, err)
+	require.NoError(t, s.Read())
+	return s
+}
+
+func TestSite_jsonLayoutsAndIncludes(t *testing.T) {
+	// layouts and includes that start with a { line don't have front matter
+	s := readTestSite(t, map[string]string{
+		"_layouts/api.html":      "{\n  \"title\": {{ page.title | jsonify }}\n}\n",
+		"_layouts/static.html":   "{\n  \"version\": 1\n}\n",
+		"_includes/json-ld.html": "{\n  \"@type\": \"WebSite\"\n}\n",
+		"api.json":               "---\nlayout: api\ntitle: API\npermalink: /api.json\n---\n",
+		"version.json":           "---\nlayout: static\npermalink: /version.json\n---\n",
+		"index.html":             "---\n---\n<script type=\"application/ld+json\">{% include json-ld.html %}</script>",
+	})
+	_, err := s.Write()
+	require.NoError(t, err)
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(s.DestDir(), name))
+		require.NoError(t, err)
+		return string(b)
+	}
+	require.Equal(t, "{\n  \"title\": \"API\"\n}\n", read("api.json"))
+	require.Equal(t, "{\n  \"version\": 1\n}\n", read("version.json"))
+	require.Contains(t, read("index.html"), "\"@type\": \"WebSite\"")
+}
@@ -17,6 +17,9 @@ func (s *Site) Write() (int, error) {
 	if err := s.setTimeZone(); err != nil {
 		return 0, err
 	}
+	if s.renderer != nil {
+		s.renderer.ClearCaches()
+	}
 	if err := s.ensureRendered(); err != nil {
 		return 0, err
 	}
@@ -48,17 +51,43 @@ func (s *Site) WriteFiles() (count int, err error) {
 			errList = append(errList, e)
 		}
 	}
-	return count, combineErrors(errList)
+	n, err := s.writeGeneratedFiles()
+	if err != nil {
+		errList = append(errList, err)
+	}
+	if len(errList) == 0 {
+		if _, err := s.writePrecompressedFiles(append(s.OutputDocs(), s.generatedFiles()...)); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return count + n, combineErrors(errList)
 }
 
-// WriteDoc writes a document to the destination directory.
-func (s *Site) WriteDoc(d Document) error {
-	from := d.Source()
+// writeGeneratedFiles writes the files that plugins added while the
+// site was rendered.
+func (s *Site) writeGeneratedFiles() (count int, err error) {
+	for _, d := range s.generatedFiles() {
+		if err := s.WriteDoc(d); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// destFilename returns the path of the document's output file.
+func (s *Site) destFilename(d Document) string {
 	rel := d.URL()
 	if !d.IsStatic() && filepath.Ext(rel) == "" {
 		rel = filepath.Join(rel, "index.html")
 	}
-	to := filepath.Join(s.DestDir(), rel)
+	return filepath.Join(s.DestDir(), rel)
+}
+
+// WriteDoc writes a document to the destination directory.
+func (s *Site) WriteDoc(d Document) error {
+	from := d.Source()
+	to := s.destFilename(d)
 	if s.cfg.Verbose {
 		log := logger.Default()
 		log.Info("create %s from %s", to, d.Source())
@@ -83,11 +112,12 @@ func (s *Site) WriteDoc(d Document) error {
 	}
 }
 
-// WriteDocument writes the rendered document.
+// WriteDocument writes the rendered document. A page from the build for
+// another language is written by that build, with its plugins.
 func (s *Site) WriteDocument(w io.Writer, d Document) error {
 	switch p := d.(type) {
 	case Page:
-		return s.WritePage(w, p)
+		return s.docBuild(p.URL(), p).WritePage(w, p)
 	default:
 		return d.Write(w)
 	}
@@ -105,8 +135,8 @@ func (s *Site) WritePage(w io.Writer, p Page) error {
 		return err
 	}
 	b := buf.Bytes()
-	err := s.runHooks(func(p plugins.Plugin) (err error) {
-		b, err = p.PostRender(b)
+	err := s.runHooks(func(h plugins.Plugin) (err error) {
+		b, err = h.PostRender(p, b)
 		return
 	})
 	if err != nil {
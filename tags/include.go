@@ -4,7 +4,12 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/osteele/liquid"
 	"github.com/osteele/liquid/render"
 )
 
@@ -18,27 +23,168 @@ func (tc tagContext) includeTag(rc render.Context) (s string, err error) {
 	return
 }
 
+// includeCachedTag renders {% include_cached %}, as jekyll-include-cache
+// does. It renders each combination of include file and arguments once,
+// and reuses the result on other pages, so an include whose output depends
+// on page variables shouldn't be cached.
+func (tc tagContext) includeCachedTag(rc render.Context) (s string, err error) {
+	for _, dir := range tc.includeDirs {
+		s, err = tc.includeCache.includeFromDir(dir, rc)
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
 func (tc tagContext) includeRelativeTag(rc render.Context) (string, error) {
 	// TODO "Note that you cannot use the ../ syntax"
 	return includeFromDir(path.Dir(rc.SourceFile()), rc)
 }
 
 func includeFromDir(dir string, rc render.Context) (string, error) {
-	argsline, err := rc.ExpandTagArg()
+	filename, include, err := includeArgs(dir, rc)
 	if err != nil {
 		return "", err
 	}
+	return rc.RenderFile(filename, map[string]interface{}{"include": include})
+}
+
+// includeArgs returns the filename and the include variable of an include
+// tag.
+func includeArgs(dir string, rc render.Context) (string, map[string]interface{}, error) {
+	argsline, err := rc.ExpandTagArg()
+	if err != nil {
+		return "", nil, err
+	}
 	args, err := ParseArgs(argsline)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if len(args.Args) != 1 {
-		return "", fmt.Errorf("parse error")
+		return "", nil, fmt.Errorf("parse error")
 	}
 	include, err := args.EvalOptions(rc)
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Join(dir, args.Args[0]), include, nil
+}
+
+// An IncludeCache holds the output of include_cached tags, by filename and
+// arguments.
+type IncludeCache struct {
+	sync.Mutex
+	m map[string]string
+}
+
+// Clear removes the cached output, so that include_cached tags render their
+// files again; for example, after the files or the site variables change.
+func (c *IncludeCache) Clear() {
+	c.Lock()
+	defer c.Unlock()
+	c.m = map[string]string{}
+}
+
+func (c *IncludeCache) includeFromDir(dir string, rc render.Context) (string, error) {
+	filename, include, err := includeArgs(dir, rc)
 	if err != nil {
 		return "", err
 	}
-	filename := filepath.Join(dir, args.Args[0])
-	return rc.RenderFile(filename, map[string]interface{}{"include": include})
+	var kb strings.Builder
+	if !writeIncludeKey(&kb, include, 0) {
+		// arguments that can't be serialized aren't cached
+		return rc.RenderFile(filename, map[string]interface{}{"include": include})
+	}
+	key := filename + "\x00" + kb.String()
+	c.Lock()
+	s, found := c.m[key]
+	c.Unlock()
+	if found {
+		return s, nil
+	}
+	s, err = rc.RenderFile(filename, map[string]interface{}{"include": include})
+	if err != nil {
+		return "", err
+	}
+	c.Lock()
+	c.m[key] = s
+	c.Unlock()
+	return s, nil
+}
+
+// maxIncludeKeyDepth limits the nesting of include_cached arguments, which
+// can be drops that refer to each other.
+const maxIncludeKeyDepth = 16
+
+// writeIncludeKey writes a serialization of an include_cached argument
+// value, for the cache key. Maps are written in key order, and pointers
+// and drops as the values they refer to, so that equal arguments have equal
+// keys. It returns false if the value can't be serialized, or is nested too
+// deeply.
+func writeIncludeKey(b *strings.Builder, v interface{}, depth int) bool {
+	if depth > maxIncludeKeyDepth {
+		return false
+	}
+	if d, ok := v.(liquid.Drop); ok {
+		v = d.ToLiquid()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			b.WriteString("nil")
+			return true
+		}
+		return writeIncludeKey(b, rv.Elem().Interface(), depth+1)
+	case reflect.Map:
+		entries := make([]string, 0, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			var eb strings.Builder
+			if !writeIncludeKey(&eb, it.Key().Interface(), depth+1) {
+				return false
+			}
+			eb.WriteByte(':')
+			if !writeIncludeKey(&eb, it.Value().Interface(), depth+1) {
+				return false
+			}
+			entries = append(entries, eb.String())
+		}
+		sort.Strings(entries)
+		b.WriteString("{" + strings.Join(entries, ",") + "}")
+	case reflect.Slice, reflect.Array:
+		b.WriteByte('[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if !writeIncludeKey(b, rv.Index(i).Interface(), depth+1) {
+				return false
+			}
+		}
+		b.WriteByte(']')
+	case reflect.Struct:
+		if s, ok := v.(fmt.Stringer); ok {
+			fmt.Fprintf(b, "%T(%q)", v, s.String())
+			return true
+		}
+		fmt.Fprintf(b, "%T{", v)
+		for i := 0; i < rv.NumField(); i++ {
+			if f := rv.Type().Field(i); f.IsExported() {
+				b.WriteString(f.Name + ":")
+				if !writeIncludeKey(b, rv.Field(i).Interface(), depth+1) {
+					return false
+				}
+				b.WriteByte(',')
+			}
+		}
+		b.WriteByte('}')
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	default:
+		fmt.Fprintf(b, "%T(%#v)", v, v)
+	}
+	return true
 }
@@ -42,3 +42,43 @@ func TestIncludeRelativeTag(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "include_relative target", strings.TrimSpace(string(s)))
 }
+
+func TestIncludeCachedTag(t *testing.T) {
+	engine := liquid.NewEngine()
+	cfg := config.Default()
+	cache := AddJekyllTags(engine, &cfg, []string{"testdata/_includes"}, func(s string) (string, bool) { return "", false })
+	render := func(src string, n int) string {
+		s, err := engine.ParseAndRenderString(src, map[string]interface{}{"page": map[string]interface{}{"n": n}})
+		require.NoError(t, err)
+		return strings.TrimSpace(s)
+	}
+
+	require.Equal(t, "a 1", render(`{% include_cached cached.html name="a" %}`, 1))
+	// the same arguments reuse the output
+	require.Equal(t, "a 1", render(`{% include_cached cached.html name="a" %}`, 2))
+	require.Equal(t, "b 2", render(`{% include_cached cached.html name="b" %}`, 2))
+	require.Equal(t, "a 2", render(`{% include cached.html name="a" %}`, 2))
+	// clearing the cache renders the file again
+	cache.Clear()
+	require.Equal(t, "a 3", render(`{% include_cached cached.html name="a" %}`, 3))
+
+	_, err := engine.ParseAndRenderString(`{% include_cached missing.html %}`, nil)
+	require.Error(t, err)
+}
+
+func TestWriteIncludeKey(t *testing.T) {
+	key := func(v interface{}) string {
+		var b strings.Builder
+		require.True(t, writeIncludeKey(&b, v, 0))
+		return b.String()
+	}
+	type item struct{ Name string }
+	// equal values that are different pointers have the same key
+	require.Equal(t, key(map[string]interface{}{"x": &item{"a"}, "y": 1}), key(map[string]interface{}{"y": 1, "x": &item{"a"}}))
+	require.NotEqual(t, key(map[string]interface{}{"x": &item{"a"}}), key(map[string]interface{}{"x": &item{"b"}}))
+	require.NotEqual(t, key(map[string]interface{}{"x": "1"}), key(map[string]interface{}{"x": 1}))
+	require.NotContains(t, key(&item{"a"}), "0x")
+
+	var b strings.Builder
+	require.False(t, writeIncludeKey(&b, func() {}, 0))
+}
@@ -3,6 +3,7 @@ package tags
 import (
 	"fmt"
 	"path"
+	"path/filepath"
 
 	"github.com/osteele/gojekyll/config"
 	"github.com/osteele/gojekyll/logger"
@@ -13,21 +14,25 @@ import (
 // A LinkTagHandler given an include tag file name returns a URL.
 type LinkTagHandler func(string) (string, bool)
 
-// AddJekyllTags adds the Jekyll tags to the Liquid engine.
-func AddJekyllTags(e *liquid.Engine, c *config.Config, includeDirs []string, lh LinkTagHandler) {
-	tc := tagContext{c, includeDirs, lh}
+// AddJekyllTags adds the Jekyll tags to the Liquid engine. It returns the
+// cache of the output of the engine's include_cached tags.
+func AddJekyllTags(e *liquid.Engine, c *config.Config, includeDirs []string, lh LinkTagHandler) *IncludeCache {
+	tc := tagContext{c, includeDirs, lh, &IncludeCache{m: map[string]string{}}}
 	e.RegisterBlock("highlight", highlightTag)
 	e.RegisterTag("include", tc.includeTag)
+	e.RegisterTag("include_cached", tc.includeCachedTag)
 	e.RegisterTag("include_relative", tc.includeRelativeTag)
 	e.RegisterTag("link", tc.linkTag)
 	e.RegisterTag("post_url", tc.postURLTag)
+	return tc.includeCache
 }
 
 // tagContext provides the context to a tag renderer.
 type tagContext struct {
-	cfg         *config.Config
-	includeDirs []string
-	lh          LinkTagHandler
+	cfg          *config.Config
+	includeDirs  []string
+	lh           LinkTagHandler
+	includeCache *IncludeCache
 }
 
 // CreateUnimplementedTag creates a tag definition that prints a warning the first
@@ -60,7 +65,7 @@ func (tc tagContext) postURLTag(rc render.Context) (string, error) {
 		url      string
 	)
 	for _, ext := range append(tc.cfg.MarkdownExtensions(), "") {
-		url, found = tc.lh(path.Join("_posts", filename+ext))
+		url, found = tc.lh(path.Join(filepath.ToSlash(tc.cfg.CollectionDir("posts")), filename+ext))
 		if found {
 			break
 		}
@@ -3,6 +3,7 @@ package utils
 import (
 	"bytes"
 	"io"
+	"strings"
 
 	"golang.org/x/net/html"
 )
@@ -44,6 +45,52 @@ outer:
 	return buf.Bytes()
 }
 
+// ReplaceHTMLText applies a filter to the text of an HTML document, except
+// within script and style elements and the elements that skip names. Unlike
+// ApplyToHTMLText, the filter receives and returns HTML, with character
+// references intact, so that it can insert elements. Like ApplyToHTMLText,
+// it only filters the text within the body element.
+func ReplaceHTMLText(doc []byte, skip []string, fn func(string) string) []byte {
+	skipped := map[string]bool{"script": true, "style": true}
+	for _, name := range skip {
+		skipped[name] = true
+	}
+	var (
+		z     = html.NewTokenizer(bytes.NewReader(doc))
+		buf   = new(bytes.Buffer)
+		body  = false
+		depth = 0 // the number of open skipped elements
+	)
+outer:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				break outer
+			}
+			panic(z.Err())
+		case html.StartTagToken, html.EndTagToken:
+			tn, _ := z.TagName()
+			switch {
+			case string(tn) == "body":
+				body = tt == html.StartTagToken
+			case skipped[string(tn)] && tt == html.StartTagToken:
+				depth++
+			case skipped[string(tn)] && depth > 0:
+				depth--
+			}
+		case html.TextToken:
+			if body && depth == 0 {
+				buf.WriteString(fn(string(z.Raw())))
+				continue outer
+			}
+		}
+		buf.Write(z.Raw())
+	}
+	return buf.Bytes()
+}
+
 // ProcessAnchorHrefs applies a filter to href attributes of anchor tags within an HTML document.
 func ProcessAnchorHrefs(doc []byte, fn func(string) string) []byte {
 	z := html.NewTokenizer(bytes.NewReader(doc))
@@ -96,3 +143,70 @@ outer:
 	}
 	return buf.Bytes()
 }
+
+// An HTMLLink is a URL in an attribute of an HTML element.
+type HTMLLink struct {
+	Tag  string // element name, e.g. "a" or "img"
+	Attr string // attribute name, e.g. "href" or "srcset"
+	URL  string
+	Line int // 1-based line number of the element's start tag
+}
+
+// HTMLLinks returns the URLs in the href, src, and srcset attributes of an
+// HTML document. It skips elements with a data-proofer-ignore attribute, as
+// html-proofer does.
+func HTMLLinks(doc []byte) (links []HTMLLink) {
+	walkHTMLStartTags(doc, func(tag html.Token, line int) {
+		for _, attr := range tag.Attr {
+			if attr.Key == "data-proofer-ignore" {
+				return
+			}
+		}
+		for _, attr := range tag.Attr {
+			switch attr.Key {
+			case "href", "src":
+				links = append(links, HTMLLink{tag.Data, attr.Key, strings.TrimSpace(attr.Val), line})
+			case "srcset":
+				for _, candidate := range strings.Split(attr.Val, ",") {
+					if fields := strings.Fields(candidate); len(fields) > 0 {
+						links = append(links, HTMLLink{tag.Data, attr.Key, fields[0], line})
+					}
+				}
+			}
+		}
+	})
+	return
+}
+
+// HTMLIDs returns the fragment identifiers that an HTML document defines:
+// the values of its id attributes, and of the name attributes of its anchors.
+func HTMLIDs(doc []byte) map[string]bool {
+	ids := map[string]bool{}
+	walkHTMLStartTags(doc, func(tag html.Token, _ int) {
+		for _, attr := range tag.Attr {
+			if attr.Key == "id" || (attr.Key == "name" && tag.Data == "a") {
+				ids[attr.Val] = true
+			}
+		}
+	})
+	return ids
+}
+
+// walkHTMLStartTags calls fn with each start tag of an HTML document, and
+// the line number that the tag starts on.
+func walkHTMLStartTags(doc []byte, fn func(html.Token, int)) {
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	line := 1
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return
+		}
+		raw := z.Raw()
+		n := bytes.Count(raw, []byte("\n"))
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			fn(z.Token(), line)
+		}
+		line += n
+	}
+}
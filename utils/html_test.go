@@ -41,3 +41,28 @@ func TestProcessAnchorHrefs(t *testing.T) {
 		})
 	}
 }
+
+func TestHTMLLinks(t *testing.T) {
+	doc := []byte("<p>\n<a href=\"/a\">A</a>\n<img src=\"b.png\" srcset=\"c.png 1x, d.png 2x\">\n<a href=\"/e\" data-proofer-ignore>E</a>")
+	require.Equal(t, []HTMLLink{
+		{"a", "href", "/a", 2},
+		{"img", "src", "b.png", 3},
+		{"img", "srcset", "c.png", 3},
+		{"img", "srcset", "d.png", 3},
+	}, HTMLLinks(doc))
+}
+
+func TestHTMLIDs(t *testing.T) {
+	ids := HTMLIDs([]byte(`<h2 id="intro">Intro</h2><a name="old"></a><input name="field">`))
+	require.Equal(t, map[string]bool{"intro": true, "old": true}, ids)
+}
+
+func TestReplaceHTMLText(t *testing.T) {
+	bracket := func(s string) string { return "[" + s + "]" }
+	replace := func(doc string) string {
+		return string(ReplaceHTMLText([]byte(doc), []string{"code", "pre"}, bracket))
+	}
+	require.Equal(t, `<body><p title="a">[b &amp; c]<code>d</code>[e]</p></body>`, replace(`<body><p title="a">b &amp; c<code>d</code>e</p></body>`))
+	require.Equal(t, `<body><pre><code>a</code>b</pre>[c]</body>`, replace(`<body><pre><code>a</code>b</pre>c</body>`))
+	require.Equal(t, `<title>a</title><body>[b]<script>c</script></body>`, replace(`<title>a</title><body>b<script>c</script></body>`))
+}
@@ -93,3 +93,38 @@ func Titleize(s string) string {
 	}
 	return strings.Join(a, " ")
 }
+
+// ClosestString returns the candidate with the smallest edit distance to s,
+// if that distance is at most maxDistance; else "". It's for "did you mean"
+// suggestions. Ties go to the alphabetically first candidate.
+func ClosestString(s string, candidates []string, maxDistance int) string {
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(s), strings.ToLower(c))
+		if d < bestDistance || (d == bestDistance && c < best) {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -74,3 +74,10 @@ func TestStringArrayToMap(t *testing.T) {
 	actual := StringArrayToMap(input)
 	require.Equal(t, expected, actual)
 }
+
+func TestClosestString(t *testing.T) {
+	candidates := []string{"title", "tags", "date"}
+	require.Equal(t, "title", ClosestString("tilte", candidates, 2))
+	require.Equal(t, "tags", ClosestString("Tag", candidates, 2))
+	require.Equal(t, "", ClosestString("permalink", candidates, 2))
+}
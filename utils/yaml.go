@@ -24,3 +24,54 @@ func UnmarshalYAMLInterface(b []byte, i *interface{}) error {
 	}
 	return nil
 }
+
+// MergeYAMLValues returns override merged over data. Mappings, whether
+// yaml.MapSlices or maps, are merged recursively; other values are replaced.
+func MergeYAMLValues(data, override interface{}) interface{} {
+	switch o := override.(type) {
+	case yaml.MapSlice:
+		d, ok := data.(yaml.MapSlice)
+		if !ok {
+			return override
+		}
+		result := append(yaml.MapSlice{}, d...)
+	loop:
+		for _, item := range o {
+			for i := range result {
+				if result[i].Key == item.Key {
+					result[i].Value = MergeYAMLValues(result[i].Value, item.Value)
+					continue loop
+				}
+			}
+			result = append(result, item)
+		}
+		return result
+	case map[string]interface{}:
+		d, ok := data.(map[string]interface{})
+		if !ok {
+			return override
+		}
+		result := make(map[string]interface{}, len(d)+len(o))
+		for k, v := range d {
+			result[k] = v
+		}
+		for k, v := range o {
+			result[k] = MergeYAMLValues(result[k], v)
+		}
+		return result
+	case map[interface{}]interface{}:
+		d, ok := data.(map[interface{}]interface{})
+		if !ok {
+			return override
+		}
+		result := make(map[interface{}]interface{}, len(d)+len(o))
+		for k, v := range d {
+			result[k] = v
+		}
+		for k, v := range o {
+			result[k] = MergeYAMLValues(result[k], v)
+		}
+		return result
+	}
+	return override
+}
@@ -34,3 +34,22 @@ func TestUnmarshalYAML(t *testing.T) {
 		require.IsType(t, d, map[interface{}]interface{}{})
 	}
 }
+
+func TestMergeYAMLValues(t *testing.T) {
+	data := yaml.MapSlice{{Key: "a", Value: 1}, {Key: "b", Value: yaml.MapSlice{{Key: "c", Value: 2}, {Key: "d", Value: 3}}}}
+	override := yaml.MapSlice{{Key: "b", Value: yaml.MapSlice{{Key: "d", Value: 4}}}, {Key: "e", Value: 5}}
+	require.Equal(t, yaml.MapSlice{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: yaml.MapSlice{{Key: "c", Value: 2}, {Key: "d", Value: 4}}},
+		{Key: "e", Value: 5},
+	}, MergeYAMLValues(data, override))
+	require.Equal(t, "x", MergeYAMLValues(data, "x"))
+
+	require.Equal(t, map[string]interface{}{
+		"a": map[interface{}]interface{}{"b": 1, "c": 3},
+		"d": 4,
+	}, MergeYAMLValues(
+		map[string]interface{}{"a": map[interface{}]interface{}{"b": 1, "c": 2}},
+		map[string]interface{}{"a": map[interface{}]interface{}{"c": 3}, "d": 4},
+	))
+}